@@ -0,0 +1,187 @@
+package concurrent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// JoinPosition defines where a new BroadcastRing subscriber starts reading.
+type JoinPosition int
+
+const (
+	// JoinHead makes the subscriber read the oldest message retained by the
+	// ring first.
+	JoinHead JoinPosition = iota
+	// JoinTail makes the subscriber read only the messages published after it
+	// has subscribed.
+	JoinTail
+)
+
+// LapError is returned to a subscriber which has fallen behind by more than
+// the ring capacity. The subscriber is moved to the oldest retained message,
+// so it may go on reading after it has handled the error.
+type LapError struct {
+	// Lost is the number of messages the subscriber has missed.
+	Lost uint64
+}
+
+// Error implements error.Error
+func (e *LapError) Error() string {
+	return fmt.Sprintf("%s: %d messages lost", ErrLapped, e.Lost)
+}
+
+// Unwrap returns ErrLapped.
+func (e *LapError) Unwrap() error {
+	return ErrLapped
+}
+
+// BroadcastRing is a safe for concurrent use fixed size ring which delivers
+// every published message to every subscriber. Each subscriber tracks its own
+// read position, so reading a message does not remove it for the others.
+// Publishing never blocks: when the ring is full the oldest message is
+// overwritten, and the subscribers which have not read it yet get a LapError.
+type BroadcastRing struct {
+	sync.RWMutex
+	buf      []interface{}
+	mask     uint64
+	tail     uint64
+	closed   bool
+	notEmpty cond
+}
+
+// NewBroadcastRing returns pointer to a new BroadcastRing instance. The
+// capacity must be power of 2.
+func NewBroadcastRing(capacity int) *BroadcastRing {
+	buf := newRingBuffer(capacity)
+	return &BroadcastRing{
+		buf:  buf,
+		mask: uint64(capacity - 1),
+	}
+}
+
+// Capacity returns the number of messages the ring retains.
+func (r *BroadcastRing) Capacity() int {
+	return len(r.buf)
+}
+
+// Size returns the number of messages currently retained by the ring.
+func (r *BroadcastRing) Size() int {
+	r.RLock()
+	defer r.RUnlock()
+	return int(r.tail - r.head())
+}
+
+// Publish appends the message e to the ring overwriting the oldest message if
+// the ring is full. Returns ErrClosed if the ring has been closed.
+func (r *BroadcastRing) Publish(e interface{}) error {
+	r.Lock()
+	defer r.Unlock()
+	if r.closed {
+		return ErrClosed
+	}
+	r.buf[r.tail&r.mask] = e
+	r.tail++
+	r.notEmpty.broadcast()
+	return nil
+}
+
+// Close closes the ring. The subscribers may read the retained messages, after
+// that they get ErrClosed.
+func (r *BroadcastRing) Close() {
+	r.Lock()
+	defer r.Unlock()
+	r.closed = true
+	r.notEmpty.broadcast()
+}
+
+// Subscribe returns a new subscriber which starts reading at the position pos.
+func (r *BroadcastRing) Subscribe(pos JoinPosition) *Subscriber {
+	r.RLock()
+	defer r.RUnlock()
+	s := &Subscriber{r: r, next: r.tail}
+	if pos == JoinHead {
+		s.next = r.head()
+	}
+	return s
+}
+
+// head returns the sequence of the oldest retained message.
+func (r *BroadcastRing) head() uint64 {
+	if n := uint64(len(r.buf)); r.tail > n {
+		return r.tail - n
+	}
+	return 0
+}
+
+// Subscriber reads the messages of a BroadcastRing. A Subscriber is not safe
+// for concurrent use, every reading goroutine should have its own one.
+type Subscriber struct {
+	r    *BroadcastRing
+	next uint64
+}
+
+// Poll returns the next message and true, or nil and false if the subscriber
+// has read all the published messages. Returns a LapError if the subscriber
+// has been lapped, and ErrClosed if the ring is closed and all the messages
+// have been read.
+func (s *Subscriber) Poll() (interface{}, bool, error) {
+	s.r.RLock()
+	defer s.r.RUnlock()
+	if s.next == s.r.tail {
+		if s.r.closed {
+			return nil, false, ErrClosed
+		}
+		return nil, false, nil
+	}
+	e, err := s.read()
+	return e, err == nil, err
+}
+
+// Take returns the next message waiting until it is published or ctx is done.
+// Returns a LapError if the subscriber has been lapped, ErrClosed if the ring
+// is closed and all the messages have been read, or the context error.
+func (s *Subscriber) Take(ctx context.Context) (interface{}, error) {
+	s.r.Lock()
+	defer s.r.Unlock()
+	for s.next == s.r.tail {
+		if s.r.closed {
+			return nil, ErrClosed
+		}
+		if err := s.r.notEmpty.wait(ctx, s.r); err != nil {
+			return nil, err
+		}
+	}
+	return s.read()
+}
+
+// Lag returns the number of published messages the subscriber has not read
+// yet, including the lost ones.
+func (s *Subscriber) Lag() uint64 {
+	s.r.RLock()
+	defer s.r.RUnlock()
+	return s.r.tail - s.next
+}
+
+// Seek moves the subscriber to the position pos.
+func (s *Subscriber) Seek(pos JoinPosition) {
+	s.r.RLock()
+	defer s.r.RUnlock()
+	if pos == JoinHead {
+		s.next = s.r.head()
+	} else {
+		s.next = s.r.tail
+	}
+}
+
+// read returns the next message, the ring must have it published.
+func (s *Subscriber) read() (interface{}, error) {
+	if h := s.r.head(); s.next < h {
+		lost := h - s.next
+		s.next = h
+		return nil, &LapError{Lost: lost}
+	}
+	e := s.r.buf[s.next&s.r.mask]
+	s.next++
+	return e, nil
+}
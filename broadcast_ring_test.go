@@ -0,0 +1,167 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBroadcastRingEverySubscriberReadsAll(t *testing.T) {
+	r := NewBroadcastRing(8)
+	a := r.Subscribe(JoinTail)
+	b := r.Subscribe(JoinTail)
+
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, r.Publish(i))
+	}
+	assert.Equal(t, 5, r.Size())
+	assert.Equal(t, uint64(5), a.Lag())
+
+	for _, s := range []*Subscriber{a, b} {
+		for i := 0; i < 5; i++ {
+			e, ok, err := s.Poll()
+			assert.Nil(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, i, e)
+		}
+		e, ok, err := s.Poll()
+		assert.Nil(t, err)
+		assert.False(t, ok)
+		assert.Nil(t, e)
+		assert.Equal(t, uint64(0), s.Lag())
+	}
+	assert.Equal(t, 5, r.Size(), "Reading should not remove messages")
+}
+
+func TestBroadcastRingJoinPosition(t *testing.T) {
+	r := NewBroadcastRing(4)
+	for i := 0; i < 6; i++ {
+		r.Publish(i)
+	}
+	assert.Equal(t, 4, r.Size())
+
+	head := r.Subscribe(JoinHead)
+	tail := r.Subscribe(JoinTail)
+
+	e, _, _ := head.Poll()
+	assert.Equal(t, 2, e, "Should start at the oldest retained message")
+	_, ok, _ := tail.Poll()
+	assert.False(t, ok)
+
+	r.Publish(6)
+	e, _, _ = tail.Poll()
+	assert.Equal(t, 6, e)
+
+	tail.Seek(JoinHead)
+	e, _, _ = tail.Poll()
+	assert.Equal(t, 3, e)
+	tail.Seek(JoinTail)
+	assert.Equal(t, uint64(0), tail.Lag())
+}
+
+func TestBroadcastRingLap(t *testing.T) {
+	r := NewBroadcastRing(4)
+	s := r.Subscribe(JoinTail)
+	for i := 0; i < 10; i++ {
+		r.Publish(i)
+	}
+	assert.Equal(t, uint64(10), s.Lag())
+
+	e, ok, err := s.Poll()
+	assert.Nil(t, e)
+	assert.False(t, ok)
+	assert.True(t, errors.Is(err, ErrLapped))
+	var lap *LapError
+	assert.True(t, errors.As(err, &lap))
+	assert.Equal(t, uint64(6), lap.Lost)
+
+	for i := 6; i < 10; i++ {
+		e, ok, err = s.Poll()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, i, e)
+	}
+
+	for i := 10; i < 15; i++ {
+		r.Publish(i)
+	}
+	_, err = s.Take(context.Background())
+	assert.True(t, errors.As(err, &lap))
+	assert.Equal(t, uint64(1), lap.Lost)
+	e, err = s.Take(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 11, e)
+}
+
+func TestBroadcastRingTake(t *testing.T) {
+	r := NewBroadcastRing(4)
+	s := r.Subscribe(JoinTail)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := s.Take(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		r.Publish(1)
+		r.Close()
+	}()
+	e, err := s.Take(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, e)
+	_, err = s.Take(context.Background())
+	assert.Equal(t, ErrClosed, err)
+	_, _, err = s.Poll()
+	assert.Equal(t, ErrClosed, err)
+	assert.Equal(t, ErrClosed, r.Publish(2))
+
+	s.Seek(JoinHead)
+	e, ok, err := s.Poll()
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 1, e, "Retained messages should be readable after close")
+}
+
+func TestBroadcastRingConcurrent(t *testing.T) {
+	const subscribers = 4
+	const n = 10000
+
+	r := NewBroadcastRing(64)
+	sums := make([]int, subscribers)
+	lost := make([]uint64, subscribers)
+	var wg sync.WaitGroup
+	for i := 0; i < subscribers; i++ {
+		s := r.Subscribe(JoinTail)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for {
+				e, err := s.Take(context.Background())
+				var lap *LapError
+				switch {
+				case errors.As(err, &lap):
+					lost[i] += lap.Lost
+				case err == ErrClosed:
+					return
+				case err != nil:
+					panic(err)
+				default:
+					sums[i] += e.(int)
+				}
+			}
+		}(i)
+	}
+	for i := 0; i < n; i++ {
+		r.Publish(1)
+	}
+	r.Close()
+	wg.Wait()
+	for i := 0; i < subscribers; i++ {
+		assert.Equal(t, n, sums[i]+int(lost[i]))
+	}
+}
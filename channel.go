@@ -0,0 +1,130 @@
+package concurrent
+
+import (
+	"context"
+)
+
+// ToChannel returns a channel which receives the elements retrieved from the
+// queue q. The pump goroutine waits for elements backing off with the
+// IdleStrategy and stops when ctx is done closing the channel.
+//
+// An element is removed from the queue only after it has been received from
+// the channel, so stopping the pump loses no elements. For this reason the
+// channel must be the only consumer of the queue. The queues which order
+// their elements other than by insertion, such as PriorityQueue, DelayQueue
+// and TransferQueue, are supported: the received element is removed even if
+// an element inserted meanwhile has taken its place at the head.
+func ToChannel(ctx context.Context, q Queue, idle IdleStrategy) <-chan interface{} {
+	hq, ok := q.(headQueue)
+	if !ok {
+		hq = fifoHead{q}
+	}
+	return pump(ctx, hq, func(ctx context.Context) (interface{}, interface{}, error) {
+		for {
+			if h, e := hq.peekHead(); h != nil {
+				return h, e, nil
+			}
+			if err := ctx.Err(); err != nil {
+				return nil, nil, err
+			}
+			idle.Idle()
+		}
+	})
+}
+
+// FromChannel inserts the elements received from the channel ch into the
+// queue q until ch is closed or ctx is done. A received element is always
+// inserted, so stopping FromChannel loses no elements; a BlockingQueue is
+// waited for room if it rejects the element. Returns ErrFull if q is not a
+// BlockingQueue and rejects an element, ErrClosed if q is closed, the context
+// error if ctx is done, or nil if ch is closed.
+func FromChannel(ctx context.Context, ch <-chan interface{}, q Queue) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if q.TryOffer(e) {
+				continue
+			}
+			bq, ok := q.(BlockingQueue)
+			if !ok {
+				return ErrFull
+			}
+			if err := bq.Put(context.Background(), e); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// NewUnboundedChannel returns a pair of channels connected by an unbounded
+// buffer: the elements sent to in are received from out in FIFO order.
+// Closing in closes out after all the buffered elements have been received.
+// Cancelling ctx closes out discarding the buffered elements.
+func NewUnboundedChannel(ctx context.Context) (chan<- interface{}, <-chan interface{}) {
+	in := make(chan interface{})
+	q := NewSynchronizedRingQueue(16)
+	go func() {
+		FromChannel(ctx, in, q)
+		q.Close()
+	}()
+	return in, q.Out(ctx)
+}
+
+// headQueue is implemented by the queues whose head may be replaced without
+// being retrieved, so the element Poll removes is not necessarily the one
+// Peek has returned before. The head is identified by a handle which allows
+// to remove that very element.
+type headQueue interface {
+	// peekHead returns the handle and the element of the head of the queue,
+	// or a nil handle if there is no element to retrieve.
+	peekHead() (h interface{}, e interface{})
+
+	// removeHead removes the element identified by the handle h. Returns
+	// false if the element is not in the queue anymore.
+	removeHead(h interface{}) bool
+}
+
+// fifoHead adapts a FIFO Queue to headQueue. With a single consumer the head
+// of such queue stays in place until it is retrieved, so the element serves
+// as its own handle. The queues which may drop their head, such as
+// SynchronizedRingQueue, implement headQueue themselves.
+type fifoHead struct {
+	Queue
+}
+
+func (q fifoHead) peekHead() (interface{}, interface{}) {
+	e := q.Peek()
+	return e, e
+}
+
+func (q fifoHead) removeHead(interface{}) bool {
+	return q.Poll() != nil
+}
+
+// pump sends the elements of the queue q to the returned channel. The peek
+// function waits for the head of the queue, which is removed once it has been
+// sent. The channel is closed when peek fails or ctx is done.
+func pump(ctx context.Context, q headQueue, peek func(ctx context.Context) (h, e interface{}, err error)) <-chan interface{} {
+	out := make(chan interface{})
+	go func() {
+		defer close(out)
+		for {
+			h, e, err := peek(ctx)
+			if err != nil {
+				return
+			}
+			select {
+			case out <- e:
+				q.removeHead(h)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
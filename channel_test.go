@@ -0,0 +1,233 @@
+package concurrent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSynchronizedRingQueueOut(t *testing.T) {
+	q := NewSynchronizedRingQueue(4)
+	q.OfferAll(1, 2, 3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := q.Out(ctx)
+	assert.Equal(t, 1, <-out)
+	assert.Equal(t, 2, <-out)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.Offer(4)
+		q.Close()
+	}()
+	var got []interface{}
+	for e := range out {
+		got = append(got, e)
+	}
+	assert.Equal(t, []interface{}{3, 4}, got, "Should deliver all elements before closing")
+	assert.Equal(t, 0, q.Size())
+	cancel()
+}
+
+func TestSynchronizedRingQueueOutCancel(t *testing.T) {
+	q := NewSynchronizedRingQueue(4)
+	q.OfferAll(1, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := q.Out(ctx)
+	assert.Equal(t, 1, <-out)
+
+	// let the pump block sending the next element
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	_, ok := <-out
+	for ok {
+		_, ok = <-out
+	}
+	assert.Equal(t, []interface{}{2}, q.ToSlice(), "Cancelled pump should not lose elements")
+}
+
+func TestSynchronizedRingQueueOutDropOldest(t *testing.T) {
+	q := NewBoundedSynchronizedRingQueue(2, OverflowDropOldest)
+	var dropped []interface{}
+	q.SetDropHandler(func(e interface{}) { dropped = append(dropped, e) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := ToChannel(ctx, q, NewSleepingIdleStrategy(time.Millisecond))
+
+	// the pump waits for the receiver holding 1 when 1 is dropped
+	q.Offer(1)
+	time.Sleep(5 * time.Millisecond)
+	q.OfferAll(2, 3)
+	assert.Equal(t, []interface{}{1}, dropped)
+
+	got := []interface{}{<-out, <-out, <-out}
+	assert.Equal(t, []interface{}{1, 2, 3}, got, "Dropped head should not make the pump lose the next element")
+	assert.Eventually(t, func() bool { return q.Size() == 0 }, time.Second, time.Millisecond)
+}
+
+func TestSynchronizedRingDequeOutOfferFirst(t *testing.T) {
+	d := NewSynchronizedRingDeque(4)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := d.Out(ctx)
+
+	// the pump waits for the receiver holding 1 when 0 takes the head
+	d.OfferLast(1)
+	time.Sleep(5 * time.Millisecond)
+	d.OfferFirst(0)
+	assert.Equal(t, 1, <-out)
+	assert.Equal(t, 0, <-out)
+	assert.Eventually(t, func() bool { return d.Size() == 0 }, time.Second, time.Millisecond)
+
+	select {
+	case e := <-out:
+		t.Fatalf("Unexpected element %v", e)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestToChannel(t *testing.T) {
+	q := NewMPMCQueue(8)
+	ctx, cancel := context.WithCancel(context.Background())
+	out := ToChannel(ctx, q, NewSleepingIdleStrategy(time.Millisecond))
+
+	q.Offer(1)
+	assert.Equal(t, 1, <-out)
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		q.Offer(2)
+	}()
+	assert.Equal(t, 2, <-out)
+
+	q.Offer(3)
+	time.Sleep(5 * time.Millisecond)
+	cancel()
+	for range out {
+	}
+	assert.Equal(t, 1, q.Size(), "Cancelled pump should not lose elements")
+}
+
+func TestToChannelPriorityQueue(t *testing.T) {
+	q := NewPriorityQueue(0, intComparator)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := ToChannel(ctx, q, NewSleepingIdleStrategy(time.Millisecond))
+
+	// the pump waits for the receiver holding 5 when 1 takes the head
+	q.Offer(5)
+	time.Sleep(5 * time.Millisecond)
+	q.Offer(1)
+	assert.Equal(t, 5, <-out)
+	assert.Equal(t, 1, <-out)
+	assert.Eventually(t, func() bool { return q.Size() == 0 }, time.Second, time.Millisecond)
+
+	select {
+	case e := <-out:
+		t.Fatalf("Unexpected element %v", e)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestToChannelDelayQueue(t *testing.T) {
+	clock := newFakeClock()
+	q := NewDelayQueue(clock)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := ToChannel(ctx, q, NewSleepingIdleStrategy(time.Millisecond))
+
+	q.OfferAfter("late", time.Hour)
+	q.OfferAfter("now", 0)
+	assert.Equal(t, "now", <-out)
+	select {
+	case e := <-out:
+		t.Fatalf("Element %v should not be sent before its deadline", e)
+	case <-time.After(10 * time.Millisecond):
+	}
+	assert.Equal(t, 1, q.Size())
+
+	clock.Advance(time.Hour)
+	assert.Equal(t, "late", <-out)
+	assert.Eventually(t, func() bool { return q.Size() == 0 }, time.Second, time.Millisecond)
+}
+
+func TestToChannelTransferQueue(t *testing.T) {
+	q := NewTransferQueue(false)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := ToChannel(ctx, q, NewSleepingIdleStrategy(time.Millisecond))
+
+	res := make(chan error)
+	go func() { res <- q.Transfer(context.Background(), 1) }()
+	assert.Equal(t, 1, <-out)
+	assert.Nil(t, <-res, "Transfer should complete once the element is received")
+	assert.Equal(t, 0, q.Size())
+}
+
+func TestFromChannel(t *testing.T) {
+	ch := make(chan interface{})
+	q := NewSynchronizedRingQueue(2)
+	go func() {
+		for i := 0; i < 5; i++ {
+			ch <- i
+		}
+		close(ch)
+	}()
+	assert.Nil(t, FromChannel(context.Background(), ch, q))
+	assert.Equal(t, []interface{}{0, 1, 2, 3, 4}, q.ToSlice())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, FromChannel(ctx, make(chan interface{}), q))
+
+	// a bounded blocking queue is waited for room
+	bq := NewBoundedSynchronizedRingQueue(2, OverflowReject)
+	ch = make(chan interface{}, 3)
+	ch <- 1
+	ch <- 2
+	ch <- 3
+	close(ch)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		bq.Poll()
+	}()
+	assert.Nil(t, FromChannel(context.Background(), ch, bq))
+	assert.Equal(t, []interface{}{2, 3}, bq.ToSlice())
+
+	bq.Close()
+	ch = make(chan interface{}, 1)
+	ch <- 4
+	assert.Equal(t, ErrClosed, FromChannel(context.Background(), ch, bq))
+
+	mq := NewMPMCQueue(2)
+	mq.OfferAll(1, 2)
+	ch <- 3
+	assert.Equal(t, ErrFull, FromChannel(context.Background(), ch, mq))
+}
+
+func TestUnboundedChannel(t *testing.T) {
+	const n = 1000
+
+	in, out := NewUnboundedChannel(context.Background())
+	for i := 0; i < n; i++ {
+		in <- i
+	}
+	close(in)
+
+	i := 0
+	for e := range out {
+		assert.Equal(t, i, e)
+		i++
+	}
+	assert.Equal(t, n, i)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	in, out = NewUnboundedChannel(ctx)
+	in <- 1
+	cancel()
+	for range out {
+	}
+}
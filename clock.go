@@ -0,0 +1,32 @@
+package concurrent
+
+import "time"
+
+// Clock provides the current time and timers. It allows the time dependent
+// collections to be driven by a fake clock in tests.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// After returns a channel which receives the current time after the
+	// duration d has elapsed.
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock is the Clock backed by the time package.
+type systemClock struct{}
+
+// NewSystemClock returns the Clock backed by the time package.
+func NewSystemClock() Clock {
+	return systemClock{}
+}
+
+// Now implements Clock.Now
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// After implements Clock.After
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
@@ -0,0 +1,57 @@
+package concurrent
+
+import "fmt"
+
+// Codec converts the elements of a persistent collection to bytes and back.
+type Codec interface {
+	// Encode returns the binary representation of the element e.
+	Encode(e interface{}) ([]byte, error)
+
+	// Decode returns the element represented by b. The codec must not retain
+	// b.
+	Decode(b []byte) (interface{}, error)
+}
+
+// bytesCodec stores []byte elements as is.
+type bytesCodec struct{}
+
+// stringCodec stores string elements as their bytes.
+type stringCodec struct{}
+
+// NewBytesCodec returns the Codec for []byte elements.
+func NewBytesCodec() Codec {
+	return bytesCodec{}
+}
+
+// NewStringCodec returns the Codec for string elements.
+func NewStringCodec() Codec {
+	return stringCodec{}
+}
+
+// Encode implements Codec.Encode
+func (bytesCodec) Encode(e interface{}) ([]byte, error) {
+	b, ok := e.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("unsupported element type %T", e)
+	}
+	return b, nil
+}
+
+// Decode implements Codec.Decode
+func (bytesCodec) Decode(b []byte) (interface{}, error) {
+	return append([]byte(nil), b...), nil
+}
+
+// Encode implements Codec.Encode
+func (stringCodec) Encode(e interface{}) ([]byte, error) {
+	s, ok := e.(string)
+	if !ok {
+		return nil, fmt.Errorf("unsupported element type %T", e)
+	}
+	return []byte(s), nil
+}
+
+// Decode implements Codec.Decode
+func (stringCodec) Decode(b []byte) (interface{}, error) {
+	return string(b), nil
+}
@@ -1,8 +1,56 @@
 package concurrent
 
+import (
+	"context"
+	"time"
+)
+
 // Equals indicates whether l is "equal to" r.
 type Equals func(l, r interface{}) bool
 
+// Less indicates whether l must sort before r.
+type Less func(l, r interface{}) bool
+
+// Comparator returns a negative number if l is less than r, zero if l is equal
+// to r, and a positive number if l is greater than r.
+type Comparator func(l, r interface{}) int
+
+// Entry is a key-value pair contained in a Map.
+type Entry struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// Versioned is implemented by collections which count their modifications.
+type Versioned interface {
+	// Version returns the number of modifications made to the collection.
+	// It may be used to check if the collection has changed since a
+	// previously obtained version.
+	Version() uint64
+}
+
+// Iterator iterates over the elements of a collection.
+//
+//	it := c.Iterator(true)
+//	for it.Next() {
+//		e := it.Value()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator interface {
+	// Next advances the iterator to the next element. Returns false when
+	// there are no more elements or the iteration has failed.
+	Next() bool
+
+	// Value returns the current element.
+	Value() interface{}
+
+	// Err returns the error which has stopped the iteration, if any.
+	Err() error
+}
+
 // Collection defines generic collection.
 type Collection interface {
 	// Size returns the collection size
@@ -15,10 +63,20 @@ type Collection interface {
 // List defines ordered collection of elements which may contain duplicates.
 type List interface {
 	Collection
+	Versioned
+
+	// Iterator returns an iterator over a snapshot of the List. If failFast is
+	// true, the iterator stops with ErrConcurrentModification once the List
+	// is modified.
+	Iterator(failFast bool) Iterator
 
 	// Add adds the element e into the List.
 	Add(e interface{})
 
+	// TryAdd adds the element e into the List. Returns ErrFull if the List is
+	// bounded and has no room for the element.
+	TryAdd(e interface{}) error
+
 	// Get returns the element specified by its index i.
 	Get(i int) interface{}
 
@@ -28,16 +86,129 @@ type List interface {
 	// Range calls f sequentially for each element present in the list.
 	// If f returns false, range stops the iteration.
 	Range(f func(e interface{}) bool)
+
+	// AddAll adds the elements es into the List stopping at the first
+	// element the List has no room for.
+	// Returns the number of the elements added.
+	AddAll(es ...interface{}) int
+
+	// RemoveAll removes all the occurrences of the elements es from the List.
+	// Returns the number of the elements removed.
+	RemoveAll(eq Equals, es ...interface{}) int
+
+	// RetainAll removes all the elements which do not satisfy the predicate f.
+	// Returns the number of the elements removed.
+	RetainAll(f func(e interface{}) bool) int
+
+	// RemoveIf removes all the elements which satisfy the predicate f.
+	// Returns the number of the elements removed.
+	RemoveIf(f func(e interface{}) bool) int
+
+	// ToSlice returns a copy of the elements contained in the List.
+	ToSlice() []interface{}
+
+	// ToSliceInto copies the elements contained in the List into dst reusing
+	// its underlying array if it has enough capacity. Returns the resulting
+	// slice.
+	ToSliceInto(dst []interface{}) []interface{}
+
+	// Insert inserts the element e at the position i shifting the subsequent
+	// elements to the right. Panics if i is out of the range [0, Size()].
+	Insert(i int, e interface{})
+
+	// TryInsert inserts the element e at the position i like Insert. Returns
+	// ErrFull if the List is bounded and has no room for the element.
+	TryInsert(i int, e interface{}) error
+
+	// Set replaces the element at the position i with the element e.
+	// Returns the replaced element. Panics if i is out of range.
+	Set(i int, e interface{}) interface{}
+
+	// RemoveAt removes the element at the position i shifting the subsequent
+	// elements to the left. Returns the removed element. Panics if i is out of
+	// range.
+	RemoveAt(i int) interface{}
+
+	// IndexOf returns the index of the first occurrence of the element e in
+	// the List, or -1 if the List does not contain the element.
+	IndexOf(e interface{}, eq Equals) int
+
+	// LastIndexOf returns the index of the last occurrence of the element e in
+	// the List, or -1 if the List does not contain the element.
+	LastIndexOf(e interface{}, eq Equals) int
+
+	// Contains returns true if the List contains the element e.
+	Contains(e interface{}, eq Equals) bool
+
+	// SubList returns a copy of the portion of the List between the positions
+	// from, inclusive, and to, exclusive. Panics if the positions are out of
+	// range.
+	SubList(from, to int) List
+
+	// TryGet returns the element at the position i and true if i is in range,
+	// otherwise returns nil and false.
+	TryGet(i int) (interface{}, bool)
+
+	// GetOrDefault returns the element at the position i if i is in range,
+	// otherwise returns def.
+	GetOrDefault(i int, def interface{}) interface{}
+
+	// First returns the first element and true if the List is not empty,
+	// otherwise returns nil and false.
+	First() (interface{}, bool)
+
+	// Last returns the last element and true if the List is not empty,
+	// otherwise returns nil and false.
+	Last() (interface{}, bool)
+
+	// PopFirst removes and returns the first element and true if the List is
+	// not empty, otherwise returns nil and false.
+	PopFirst() (interface{}, bool)
+
+	// PopLast removes and returns the last element and true if the List is
+	// not empty, otherwise returns nil and false.
+	PopLast() (interface{}, bool)
+
+	// Sort sorts the List in place according to less.
+	Sort(less Less)
+
+	// SortStable sorts the List in place according to less keeping the
+	// original order of equal elements.
+	SortStable(less Less)
+
+	// BinarySearch searches the List sorted according to cmp for the element
+	// e. Returns the index of the element and true if it is found, otherwise
+	// returns the index where the element would be inserted and false.
+	BinarySearch(e interface{}, cmp Comparator) (int, bool)
+
+	// InsertSorted inserts the element e into the List sorted according to
+	// cmp keeping the order. Equal elements are inserted after the existing
+	// ones. Returns the index of the inserted element.
+	InsertSorted(e interface{}, cmp Comparator) int
+
+	// TryInsertSorted inserts the element e into the List like InsertSorted.
+	// Returns the index of the inserted element, or ErrFull if the List is
+	// bounded and has no room for the element.
+	TryInsertSorted(e interface{}, cmp Comparator) (int, error)
 }
 
 // Queue defines ordered in FIFO manner collection of elements which may contain
 // duplicates.
+//
+// The bulk removal operations RemoveAll, RetainAll and RemoveIf are not part
+// of Queue, since the lock-free queues cannot remove elements from the middle;
+// the lock-based SynchronizedRingQueue and SynchronizedRingDeque provide them.
+// OfferAll is the bulk insertion of Queue.
 type Queue interface {
 	Collection
 
 	// Offer inserts the element e into the Queue.
 	Offer(e interface{})
 
+	// TryOffer inserts the element e into the Queue.
+	// Returns false if the element cannot be inserted.
+	TryOffer(e interface{}) bool
+
 	// Poll retrieves and removes the head of the Queue; returns nil if the
 	// queue is empty.
 	Poll() interface{}
@@ -49,11 +220,111 @@ type Queue interface {
 	// Range calls f sequentially for each element present in the queue.
 	// If f returns false, range stops the iteration.
 	Range(f func(e interface{}) bool)
+
+	// ToSlice returns a copy of the elements contained in the queue in FIFO order.
+	ToSlice() []interface{}
+
+	// ToSliceInto copies the elements contained in the queue in FIFO order into dst reusing
+	// its underlying array if it has enough capacity. Returns the resulting
+	// slice.
+	ToSliceInto(dst []interface{}) []interface{}
+
+	// Drain removes up to max elements from the head of the queue and calls f
+	// sequentially for each of them. If max is not positive, all the elements
+	// are removed. Returns the number of the elements removed.
+	Drain(max int, f func(e interface{})) int
+
+	// DrainTo removes up to len(dst) elements from the head of the queue and
+	// stores them into dst. Returns the number of the elements removed.
+	DrainTo(dst []interface{}) int
+
+	// OfferAll inserts the elements es into the queue.
+	// Returns the number of the elements inserted.
+	OfferAll(es ...interface{}) int
+}
+
+// Deque defines ordered collection of elements which may contain duplicates
+// and supports insertion and removal at both ends.
+type Deque interface {
+	Queue
+
+	// OfferFirst inserts the element e at the head of the Deque.
+	OfferFirst(e interface{})
+
+	// OfferLast inserts the element e at the tail of the Deque.
+	OfferLast(e interface{})
+
+	// TryOfferFirst inserts the element e at the head of the Deque.
+	// Returns false if the element cannot be inserted.
+	TryOfferFirst(e interface{}) bool
+
+	// TryOfferLast inserts the element e at the tail of the Deque.
+	// Returns false if the element cannot be inserted.
+	TryOfferLast(e interface{}) bool
+
+	// PollFirst retrieves and removes the head of the Deque; returns nil if
+	// the Deque is empty.
+	PollFirst() interface{}
+
+	// PollLast retrieves and removes the tail of the Deque; returns nil if
+	// the Deque is empty.
+	PollLast() interface{}
+
+	// PeekFirst retrieves, but does not remove, the head of the Deque;
+	// returns nil if the Deque is empty.
+	PeekFirst() interface{}
+
+	// PeekLast retrieves, but does not remove, the tail of the Deque;
+	// returns nil if the Deque is empty.
+	PeekLast() interface{}
+
+	// RangeReverse calls f sequentially for each element present in the
+	// Deque starting from the tail. If f returns false, range stops the
+	// iteration.
+	RangeReverse(f func(e interface{}) bool)
+}
+
+// BlockingQueue defines Queue which additionally supports operations that
+// wait for the queue to become non-empty when retrieving an element, and wait
+// for space to become available when storing an element.
+type BlockingQueue interface {
+	Queue
+
+	// Put inserts the element e into the queue waiting if necessary for space
+	// to become available. Returns ErrClosed if the queue is closed, or the
+	// context error if ctx is done before the element is inserted.
+	Put(ctx context.Context, e interface{}) error
+
+	// Take retrieves and removes the head of the queue waiting if necessary
+	// until an element becomes available. Returns ErrClosed if the queue is
+	// closed and empty, or the context error if ctx is done before an element
+	// becomes available.
+	Take(ctx context.Context) (interface{}, error)
+
+	// OfferTimeout inserts the element e into the queue waiting up to the
+	// duration d for space to become available. Returns false if the element
+	// has not been inserted.
+	OfferTimeout(e interface{}, d time.Duration) bool
+
+	// PollTimeout retrieves and removes the head of the queue waiting up to
+	// the duration d for an element to become available. Returns false if no
+	// element has been retrieved.
+	PollTimeout(d time.Duration) (interface{}, bool)
+
+	// Close closes the queue. The closed queue rejects new elements, the
+	// elements already in the queue can still be retrieved.
+	Close()
 }
 
 // Set defines unordered collection of element which may not contain duplicates.
 type Set interface {
 	Collection
+	Versioned
+
+	// Iterator returns an iterator over a snapshot of the set. If failFast is
+	// true, the iterator stops with ErrConcurrentModification once the set
+	// is modified.
+	Iterator(failFast bool) Iterator
 
 	// Add adds the element e into the set.
 	Add(e interface{})
@@ -67,11 +338,41 @@ type Set interface {
 
 	// Remove removes the element e from the set if it is present.
 	Remove(e interface{})
+
+	// AddAll adds the elements es into the set.
+	// Returns the number of the elements which were not present in the set.
+	AddAll(es ...interface{}) int
+
+	// RemoveAll removes the elements es from the set.
+	// Returns the number of the elements removed.
+	RemoveAll(es ...interface{}) int
+
+	// RetainAll removes all the elements which do not satisfy the predicate f.
+	// Returns the number of the elements removed.
+	RetainAll(f func(e interface{}) bool) int
+
+	// RemoveIf removes all the elements which satisfy the predicate f.
+	// Returns the number of the elements removed.
+	RemoveIf(f func(e interface{}) bool) int
+
+	// ToSlice returns a copy of the elements contained in the set.
+	ToSlice() []interface{}
+
+	// ToSliceInto copies the elements contained in the set into dst reusing
+	// its underlying array if it has enough capacity. Returns the resulting
+	// slice.
+	ToSliceInto(dst []interface{}) []interface{}
 }
 
 // Map represents a collection of key-value pairs.
 type Map interface {
 	Collection
+	Versioned
+
+	// Iterator returns an iterator over a snapshot of the map entries. The
+	// iterator values are of the Entry type. If failFast is true, the iterator
+	// stops with ErrConcurrentModification once the map is modified.
+	Iterator(failFast bool) Iterator
 
 	// Put puts a new key-value pair into the Map.
 	// If the key already exists overwrites the existing value with the new one.
@@ -105,4 +406,29 @@ type Map interface {
 
 	// Keys returns the keys contained in the map.
 	Keys() []interface{}
+
+	// Values returns the values contained in the map.
+	Values() []interface{}
+
+	// Entries returns the key-value pairs contained in the map.
+	Entries() []Entry
+
+	// ToMap returns a copy of the map.
+	ToMap() map[interface{}]interface{}
+
+	// PutAll puts all the key-value pairs from the map src into the Map.
+	// Returns the number of the pairs put.
+	PutAll(src map[interface{}]interface{}) int
+
+	// RemoveAll removes the key-value pairs specified by the keys ks from the
+	// Map. Returns the number of the pairs removed.
+	RemoveAll(ks ...interface{}) int
+
+	// RetainAll removes all the key-value pairs which do not satisfy the
+	// predicate f. Returns the number of the pairs removed.
+	RetainAll(f func(k, v interface{}) bool) int
+
+	// RemoveIf removes all the key-value pairs which satisfy the predicate f.
+	// Returns the number of the pairs removed.
+	RemoveIf(f func(k, v interface{}) bool) int
 }
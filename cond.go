@@ -0,0 +1,48 @@
+package concurrent
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// cond is a condition variable which waiting can be cancelled by a context.
+// The zero value is ready to use. The methods must be called with the lock
+// protecting the condition held.
+type cond struct {
+	ch chan struct{}
+}
+
+// wait atomically unlocks l and suspends the calling goroutine until
+// broadcast is called or ctx is done. wait locks l before returning.
+func (c *cond) wait(ctx context.Context, l sync.Locker) error {
+	return c.waitTimer(ctx, l, nil)
+}
+
+// waitTimer is like wait, but it also returns nil once the timer channel
+// receives a value. A nil timer never fires.
+func (c *cond) waitTimer(ctx context.Context, l sync.Locker, timer <-chan time.Time) error {
+	if c.ch == nil {
+		c.ch = make(chan struct{})
+	}
+	ch := c.ch
+	l.Unlock()
+	defer l.Lock()
+
+	select {
+	case <-ch:
+		return nil
+	case <-timer:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// broadcast wakes all the goroutines waiting on c.
+func (c *cond) broadcast() {
+	if c.ch != nil {
+		close(c.ch)
+		c.ch = nil
+	}
+}
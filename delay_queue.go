@@ -0,0 +1,266 @@
+package concurrent
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DelayQueue is a safe for concurrent use unbounded BlockingQueue
+// implementation in which every element carries a deadline. An element can
+// be retrieved only after its deadline has expired; the head of the queue is
+// the element with the earliest deadline.
+//
+// Size, Range and ToSlice take into account all the elements including the
+// not expired ones.
+type DelayQueue struct {
+	sync.RWMutex
+	pq       *PriorityQueue
+	clock    Clock
+	closed   bool
+	notEmpty cond
+}
+
+// NewDelayQueue returns pointer to a new DelayQueue instance which uses the
+// clock to check the deadlines. If clock is nil, the system clock is used.
+func NewDelayQueue(clock Clock) *DelayQueue {
+	if clock == nil {
+		clock = NewSystemClock()
+	}
+	return &DelayQueue{
+		pq:    NewPriorityQueue(0, compareTime),
+		clock: clock,
+	}
+}
+
+// Size implements Queue.Size
+func (q *DelayQueue) Size() int {
+	q.RLock()
+	defer q.RUnlock()
+	return len(q.pq.heap)
+}
+
+// Clear implements Queue.Clear
+func (q *DelayQueue) Clear() {
+	q.Lock()
+	defer q.Unlock()
+	q.pq.Clear()
+}
+
+// Offer implements Queue.Offer
+func (q *DelayQueue) Offer(e interface{}) {
+	q.TryOffer(e)
+}
+
+// TryOffer implements Queue.TryOffer. The element becomes available immediately.
+func (q *DelayQueue) TryOffer(e interface{}) bool {
+	return q.OfferAt(e, q.clock.Now()) != nil
+}
+
+// OfferAt inserts the element e which becomes available at the time t.
+// Returns the handle which can be used to cancel the element, or nil if the
+// queue is closed.
+func (q *DelayQueue) OfferAt(e interface{}, t time.Time) *PriorityHandle {
+	q.Lock()
+	defer q.Unlock()
+	return q.offer(e, t)
+}
+
+// OfferAfter inserts the element e which becomes available after the
+// duration d. Returns the handle which can be used to cancel the element, or
+// nil if the queue is closed.
+func (q *DelayQueue) OfferAfter(e interface{}, d time.Duration) *PriorityHandle {
+	return q.OfferAt(e, q.clock.Now().Add(d))
+}
+
+// Cancel removes the element identified by the handle h from the queue.
+// Returns false if the element is not in the queue anymore.
+func (q *DelayQueue) Cancel(h *PriorityHandle) bool {
+	q.Lock()
+	defer q.Unlock()
+	if !q.pq.owns(h) {
+		return false
+	}
+	q.pq.removeAt(h.index)
+	return true
+}
+
+// Poll implements Queue.Poll. Returns nil if there is no expired element.
+func (q *DelayQueue) Poll() interface{} {
+	q.Lock()
+	defer q.Unlock()
+	if !q.expired(q.clock.Now()) {
+		return nil
+	}
+	return q.pq.removeAt(0).e
+}
+
+// Peek implements Queue.Peek. Returns the element with the earliest deadline
+// even if it has not expired yet.
+func (q *DelayQueue) Peek() interface{} {
+	q.RLock()
+	defer q.RUnlock()
+	if len(q.pq.heap) == 0 {
+		return nil
+	}
+	return q.pq.heap[0].e
+}
+
+// Range implements Queue.Range. The elements are visited in no particular
+// order.
+func (q *DelayQueue) Range(f func(e interface{}) bool) {
+	q.RLock()
+	defer q.RUnlock()
+	for _, h := range q.pq.heap {
+		if !f(h.e) {
+			return
+		}
+	}
+}
+
+// ToSlice implements Queue.ToSlice. The elements are returned in deadline
+// order.
+func (q *DelayQueue) ToSlice() []interface{} {
+	return q.ToSliceInto(nil)
+}
+
+// ToSliceInto implements Queue.ToSliceInto. The elements are copied in
+// deadline order.
+func (q *DelayQueue) ToSliceInto(dst []interface{}) []interface{} {
+	q.RLock()
+	defer q.RUnlock()
+	return q.pq.ToSliceInto(dst)
+}
+
+// Drain implements Queue.Drain. Only the expired elements are removed.
+func (q *DelayQueue) Drain(max int, f func(e interface{})) int {
+	q.Lock()
+	var buf []interface{}
+	now := q.clock.Now()
+	for (max <= 0 || len(buf) < max) && q.expired(now) {
+		buf = append(buf, q.pq.removeAt(0).e)
+	}
+	q.Unlock()
+
+	for _, e := range buf {
+		f(e)
+	}
+	return len(buf)
+}
+
+// DrainTo implements Queue.DrainTo. Only the expired elements are removed.
+func (q *DelayQueue) DrainTo(dst []interface{}) int {
+	q.Lock()
+	defer q.Unlock()
+	n := 0
+	now := q.clock.Now()
+	for n < len(dst) && q.expired(now) {
+		dst[n] = q.pq.removeAt(0).e
+		n++
+	}
+	return n
+}
+
+// OfferAll implements Queue.OfferAll. The elements become available
+// immediately.
+func (q *DelayQueue) OfferAll(es ...interface{}) int {
+	q.Lock()
+	defer q.Unlock()
+	if q.closed {
+		return 0
+	}
+	now := q.clock.Now()
+	for _, e := range es {
+		q.offer(e, now)
+	}
+	return len(es)
+}
+
+// Put implements BlockingQueue.Put. The queue is unbounded, so Put never
+// waits. The element becomes available immediately.
+func (q *DelayQueue) Put(ctx context.Context, e interface{}) error {
+	if !q.TryOffer(e) {
+		return ErrClosed
+	}
+	return nil
+}
+
+// Take implements BlockingQueue.Take. Waits until the earliest deadline
+// expires.
+func (q *DelayQueue) Take(ctx context.Context) (interface{}, error) {
+	q.Lock()
+	defer q.Unlock()
+	for {
+		if len(q.pq.heap) == 0 {
+			if q.closed {
+				return nil, ErrClosed
+			}
+			if err := q.notEmpty.wait(ctx, q); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		d := q.pq.heap[0].priority.(time.Time).Sub(q.clock.Now())
+		if d <= 0 {
+			return q.pq.removeAt(0).e, nil
+		}
+		if err := q.notEmpty.waitTimer(ctx, q, q.clock.After(d)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// OfferTimeout implements BlockingQueue.OfferTimeout
+func (q *DelayQueue) OfferTimeout(e interface{}, d time.Duration) bool {
+	return q.TryOffer(e)
+}
+
+// PollTimeout implements BlockingQueue.PollTimeout
+func (q *DelayQueue) PollTimeout(d time.Duration) (interface{}, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	e, err := q.Take(ctx)
+	return e, err == nil
+}
+
+// Close implements BlockingQueue.Close. The elements already in the queue are
+// still retrieved after their deadlines expire.
+func (q *DelayQueue) Close() {
+	q.Lock()
+	defer q.Unlock()
+	q.closed = true
+	q.notEmpty.broadcast()
+}
+
+func (q *DelayQueue) offer(e interface{}, t time.Time) *PriorityHandle {
+	if q.closed {
+		return nil
+	}
+	h := q.pq.add(e, t)
+	q.notEmpty.broadcast()
+	return h
+}
+
+// peekHead returns the head of the queue only if it has expired.
+func (q *DelayQueue) peekHead() (interface{}, interface{}) {
+	q.RLock()
+	defer q.RUnlock()
+	if !q.expired(q.clock.Now()) {
+		return nil, nil
+	}
+	return q.pq.heap[0], q.pq.heap[0].e
+}
+
+// removeHead removes the element identified by the handle h.
+func (q *DelayQueue) removeHead(h interface{}) bool {
+	return q.Cancel(h.(*PriorityHandle))
+}
+
+// expired returns true if the head of the queue has expired at the time now.
+func (q *DelayQueue) expired(now time.Time) bool {
+	return len(q.pq.heap) > 0 && !q.pq.heap[0].priority.(time.Time).After(now)
+}
+
+func compareTime(l, r interface{}) int {
+	return l.(time.Time).Compare(r.(time.Time))
+}
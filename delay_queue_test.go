@@ -0,0 +1,188 @@
+package concurrent
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock is a Clock which time advances only by Advance.
+type fakeClock struct {
+	sync.Mutex
+	now     time.Time
+	waiters []fakeTimer
+}
+
+type fakeTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(0, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.Lock()
+	defer c.Unlock()
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, fakeTimer{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+	c.now = c.now.Add(d)
+	ws := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			ws = append(ws, w)
+		} else {
+			w.ch <- c.now
+		}
+	}
+	c.waiters = ws
+}
+
+// timers returns the number of timers not fired yet.
+func (c *fakeClock) timers() int {
+	c.Lock()
+	defer c.Unlock()
+	return len(c.waiters)
+}
+
+func TestDelayQueuePoll(t *testing.T) {
+	clock := newFakeClock()
+	q := NewDelayQueue(clock)
+	var _ BlockingQueue = q
+
+	q.OfferAfter("c", 3*time.Second)
+	q.OfferAfter("a", time.Second)
+	q.OfferAt("b", clock.Now().Add(2*time.Second))
+
+	assert.Equal(t, 3, q.Size())
+	assert.Equal(t, "a", q.Peek())
+	assert.Nil(t, q.Poll(), "Should not return not expired elements")
+	assert.Equal(t, []interface{}{"a", "b", "c"}, q.ToSlice())
+
+	clock.Advance(time.Second)
+	assert.Equal(t, "a", q.Poll())
+	assert.Nil(t, q.Poll())
+
+	clock.Advance(5 * time.Second)
+	assert.Equal(t, "b", q.Poll())
+	assert.Equal(t, "c", q.Poll())
+	assert.Nil(t, q.Poll())
+	assert.Nil(t, q.Peek())
+
+	assert.True(t, q.TryOffer("now"))
+	assert.Equal(t, "now", q.Poll())
+}
+
+func TestDelayQueueCancel(t *testing.T) {
+	clock := newFakeClock()
+	q := NewDelayQueue(clock)
+
+	a := q.OfferAfter("a", time.Second)
+	b := q.OfferAfter("b", 2*time.Second)
+	assert.Equal(t, "a", a.Value())
+
+	assert.True(t, q.Cancel(a))
+	assert.False(t, q.Cancel(a))
+	assert.Equal(t, 1, q.Size())
+
+	clock.Advance(2 * time.Second)
+	assert.Equal(t, "b", q.Poll())
+	assert.False(t, q.Cancel(b), "Retrieved element should not be cancellable")
+	assert.False(t, q.Cancel(nil))
+}
+
+func TestDelayQueueDrain(t *testing.T) {
+	clock := newFakeClock()
+	q := NewDelayQueue(clock)
+	for i := 1; i <= 5; i++ {
+		q.OfferAfter(i, time.Duration(i)*time.Second)
+	}
+
+	clock.Advance(3 * time.Second)
+	var got []interface{}
+	assert.Equal(t, 2, q.Drain(2, func(e interface{}) { got = append(got, e) }))
+	assert.Equal(t, []interface{}{1, 2}, got)
+
+	dst := make([]interface{}, 5)
+	assert.Equal(t, 1, q.DrainTo(dst), "Should drain only expired elements")
+	assert.Equal(t, 3, dst[0])
+	assert.Equal(t, 2, q.Size())
+
+	assert.Equal(t, 2, q.OfferAll(6, 7))
+	assert.Equal(t, 2, q.Drain(0, func(interface{}) {}))
+
+	q.Clear()
+	assert.Equal(t, 0, q.Size())
+}
+
+func TestDelayQueueTake(t *testing.T) {
+	clock := newFakeClock()
+	q := NewDelayQueue(clock)
+	q.OfferAfter("late", time.Minute)
+
+	res := make(chan interface{})
+	go func() {
+		e, err := q.Take(context.Background())
+		assert.Nil(t, err)
+		res <- e
+	}()
+
+	// wait for Take to arm the timer for the head
+	assert.Eventually(t, func() bool { return clock.timers() > 0 }, time.Second, time.Millisecond)
+
+	// an earlier element wakes Take up to re-arm the timer
+	q.OfferAfter("early", time.Second)
+	assert.Eventually(t, func() bool { return clock.timers() > 1 }, time.Second, time.Millisecond)
+
+	clock.Advance(time.Second)
+	assert.Equal(t, "early", <-res)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		assert.Eventually(t, func() bool { return clock.timers() > 1 }, time.Second, time.Millisecond)
+		cancel()
+	}()
+	_, err := q.Take(ctx)
+	assert.Equal(t, context.Canceled, err)
+
+	q.Close()
+	assert.Nil(t, q.OfferAfter("x", 0))
+	assert.Equal(t, ErrClosed, q.Put(context.Background(), "x"))
+
+	go clock.Advance(time.Minute)
+	e, err := q.Take(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "late", e)
+	_, err = q.Take(context.Background())
+	assert.Equal(t, ErrClosed, err)
+}
+
+func TestDelayQueueSystemClock(t *testing.T) {
+	q := NewDelayQueue(nil)
+	start := time.Now()
+	q.OfferAfter(1, 20*time.Millisecond)
+
+	_, ok := q.PollTimeout(time.Millisecond)
+	assert.False(t, ok)
+
+	e, err := q.Take(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, e)
+	assert.True(t, time.Since(start) >= 20*time.Millisecond)
+}
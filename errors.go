@@ -0,0 +1,26 @@
+package concurrent
+
+import "errors"
+
+// ErrClosed is returned when an element is put into a closed queue, or taken
+// from a closed and drained queue.
+var ErrClosed = errors.New("queue is closed")
+
+// ErrFull is returned when an element cannot be added into a bounded
+// collection because it has reached its capacity.
+var ErrFull = errors.New("collection is full")
+
+// ErrConcurrentModification is returned by a fail-fast Iterator when the
+// underlying collection has been modified during the iteration.
+var ErrConcurrentModification = errors.New("concurrent modification")
+
+// ErrAlerted is returned by a SequenceBarrier which has been alerted.
+var ErrAlerted = errors.New("barrier is alerted")
+
+// ErrLapped is wrapped by the LapError returned to a BroadcastRing subscriber
+// which has fallen behind by more than the ring capacity.
+var ErrLapped = errors.New("subscriber is lapped")
+
+// ErrCorrupted is returned when a persistent collection reads data which
+// fails the integrity check.
+var ErrCorrupted = errors.New("data is corrupted")
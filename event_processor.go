@@ -0,0 +1,152 @@
+package concurrent
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+// EventHandler handles the events published into a RingBuffer.
+type EventHandler interface {
+	// OnEvent handles the event published for the sequence seq. The flag
+	// endOfBatch is true for the last event of the batch which has been
+	// available to the processor at once; it may be used to flush the work
+	// done for the batch.
+	OnEvent(e interface{}, seq int64, endOfBatch bool) error
+}
+
+// EventHandlerFunc is an adapter to use an ordinary function as EventHandler.
+type EventHandlerFunc func(e interface{}, seq int64, endOfBatch bool) error
+
+// ExceptionHandler handles the errors returned, or the panics raised, by an
+// EventHandler.
+type ExceptionHandler interface {
+	// HandleEventError handles the error err returned for the event e
+	// published for the sequence seq.
+	HandleEventError(err error, e interface{}, seq int64)
+}
+
+// ExceptionHandlerFunc is an adapter to use an ordinary function as
+// ExceptionHandler.
+type ExceptionHandlerFunc func(err error, e interface{}, seq int64)
+
+// EventProcessor runs an EventHandler for the events of a RingBuffer made
+// available by a SequenceBarrier. The events are handled in batches: the
+// processor takes all the events available at once and updates its sequence
+// after the batch.
+type EventProcessor struct {
+	rb       *RingBuffer
+	barrier  *SequenceBarrier
+	handler  EventHandler
+	onError  ExceptionHandler
+	sequence *Sequence
+	state    atomic.Int32
+}
+
+// The states of an EventProcessor.
+const (
+	processorIdle int32 = iota
+	processorRunning
+	processorHalted
+)
+
+// OnEvent implements EventHandler.OnEvent
+func (f EventHandlerFunc) OnEvent(e interface{}, seq int64, endOfBatch bool) error {
+	return f(e, seq, endOfBatch)
+}
+
+// HandleEventError implements ExceptionHandler.HandleEventError
+func (f ExceptionHandlerFunc) HandleEventError(err error, e interface{}, seq int64) {
+	f(err, e, seq)
+}
+
+// NewEventProcessor returns pointer to a new EventProcessor instance which
+// handles the events of the ring buffer rb made available by the barrier.
+func NewEventProcessor(rb *RingBuffer, barrier *SequenceBarrier, handler EventHandler) *EventProcessor {
+	return &EventProcessor{
+		rb:       rb,
+		barrier:  barrier,
+		handler:  handler,
+		sequence: NewSequence(),
+	}
+}
+
+// Sequence returns the sequence of the last event handled by the processor.
+// It is used as a dependent of the downstream barriers and as a gating
+// sequence of the ring buffer.
+func (p *EventProcessor) Sequence() *Sequence {
+	return p.sequence
+}
+
+// SetExceptionHandler sets the handler of the event handler errors. If the
+// exception handler is set, the processor skips the failed event and goes on,
+// otherwise Run stops and returns the error. Must be called before Run.
+func (p *EventProcessor) SetExceptionHandler(h ExceptionHandler) {
+	p.onError = h
+}
+
+// Run handles the events until ctx is done, the processor is halted, or the
+// event handler fails without an exception handler set. Returns the context
+// error, nil if the processor has been halted, or the handler error. If Halt
+// has been called before Run, Run returns nil immediately.
+func (p *EventProcessor) Run(ctx context.Context) error {
+	if !p.state.CompareAndSwap(processorIdle, processorRunning) {
+		if p.state.CompareAndSwap(processorHalted, processorIdle) {
+			p.barrier.ClearAlert()
+			return nil
+		}
+		panic("event processor is already running")
+	}
+	defer p.state.Store(processorIdle)
+
+	// clear the alert left by a previous run unless Halt has been called
+	// in the meantime
+	p.barrier.ClearAlert()
+	if p.state.Load() == processorHalted {
+		return nil
+	}
+	stop := context.AfterFunc(ctx, p.barrier.Alert)
+	defer stop()
+
+	next := p.sequence.Get() + 1
+	for {
+		avail, err := p.barrier.WaitFor(next)
+		if err != nil {
+			return ctx.Err()
+		}
+		for ; next <= avail; next++ {
+			e := p.rb.Get(next)
+			if err := p.handle(e, next, next == avail); err != nil {
+				if p.onError == nil {
+					p.sequence.Set(next - 1)
+					return err
+				}
+				p.onError.HandleEventError(err, e, next)
+			}
+		}
+		p.sequence.Set(avail)
+	}
+}
+
+// Halt stops the running processor after the current batch. If the processor
+// is not running, the next Run returns immediately.
+func (p *EventProcessor) Halt() {
+	p.state.CompareAndSwap(processorIdle, processorHalted)
+	p.state.CompareAndSwap(processorRunning, processorHalted)
+	p.barrier.Alert()
+}
+
+// IsRunning returns true if the processor is running and has not been halted.
+func (p *EventProcessor) IsRunning() bool {
+	return p.state.Load() == processorRunning
+}
+
+// handle calls the event handler converting a panic into an error.
+func (p *EventProcessor) handle(e interface{}, seq int64, endOfBatch bool) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("event handler panic: %v", r)
+		}
+	}()
+	return p.handler.OnEvent(e, seq, endOfBatch)
+}
@@ -0,0 +1,138 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func waitForSequence(t *testing.T, s *Sequence, seq int64) {
+	assert.Eventually(t, func() bool { return s.Get() >= seq }, 5*time.Second, time.Millisecond)
+}
+
+func TestEventProcessorPipeline(t *testing.T) {
+	const n = 10000
+
+	rb := NewRingBuffer(16, newTestEvent, SingleProducer, NewIdleWaitStrategy(NewYeildingIdleStrategy()))
+
+	// stage A doubles the value, stage B must see the doubled value
+	a := NewEventProcessor(rb, rb.NewBarrier(), EventHandlerFunc(func(e interface{}, seq int64, _ bool) error {
+		ev := e.(*testEvent)
+		ev.doubled = ev.value * 2
+		return nil
+	}))
+	var sum int64
+	batches := 0
+	b := NewEventProcessor(rb, rb.NewBarrier(a.Sequence()), EventHandlerFunc(func(e interface{}, seq int64, endOfBatch bool) error {
+		ev := e.(*testEvent)
+		if ev.doubled != ev.value*2 {
+			return errors.New("stage A has not processed the event")
+		}
+		sum += ev.doubled
+		if endOfBatch {
+			batches++
+		}
+		return nil
+	}))
+	rb.AddGatingSequences(b.Sequence())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make(chan error, 2)
+	go func() { errs <- a.Run(ctx) }()
+	go func() { errs <- b.Run(ctx) }()
+
+	for i := int64(1); i <= n; i++ {
+		rb.PublishEvent(func(e interface{}, seq int64) {
+			ev := e.(*testEvent)
+			ev.value = i
+			ev.doubled = 0
+		})
+	}
+
+	waitForSequence(t, b.Sequence(), n-1)
+	assert.Equal(t, int64(n*(n+1)), sum)
+	assert.True(t, batches > 0 && batches <= n)
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-errs)
+	assert.Equal(t, context.Canceled, <-errs)
+}
+
+func TestEventProcessorExceptionHandler(t *testing.T) {
+	rb := NewRingBuffer(8, newTestEvent, SingleProducer, NewIdleWaitStrategy(NewYeildingIdleStrategy()))
+
+	p := NewEventProcessor(rb, rb.NewBarrier(), EventHandlerFunc(func(e interface{}, seq int64, _ bool) error {
+		switch seq {
+		case 1:
+			return errors.New("failed")
+		case 2:
+			panic("boom")
+		}
+		return nil
+	}))
+	var failed []int64
+	p.SetExceptionHandler(ExceptionHandlerFunc(func(err error, e interface{}, seq int64) {
+		failed = append(failed, seq)
+	}))
+	rb.AddGatingSequences(p.Sequence())
+
+	done := make(chan error)
+	go func() { done <- p.Run(context.Background()) }()
+
+	for i := 0; i < 4; i++ {
+		rb.PublishEvent(func(interface{}, int64) {})
+	}
+	waitForSequence(t, p.Sequence(), 3)
+	assert.True(t, p.IsRunning())
+
+	p.Halt()
+	assert.Nil(t, <-done)
+	assert.False(t, p.IsRunning())
+	assert.Equal(t, []int64{1, 2}, failed)
+}
+
+func TestEventProcessorFatalError(t *testing.T) {
+	rb := NewRingBuffer(8, newTestEvent, SingleProducer, NewIdleWaitStrategy(NewYeildingIdleStrategy()))
+
+	fail := errors.New("failed")
+	p := NewEventProcessor(rb, rb.NewBarrier(), EventHandlerFunc(func(e interface{}, seq int64, _ bool) error {
+		if seq == 1 {
+			return fail
+		}
+		return nil
+	}))
+	rb.AddGatingSequences(p.Sequence())
+
+	for i := 0; i < 3; i++ {
+		rb.PublishEvent(func(interface{}, int64) {})
+	}
+	assert.Equal(t, fail, p.Run(context.Background()))
+	assert.Equal(t, int64(0), p.Sequence().Get(), "Failed event should not be published as processed")
+}
+
+func TestEventProcessorHaltBeforeRun(t *testing.T) {
+	rb := NewRingBuffer(8, newTestEvent, SingleProducer, NewIdleWaitStrategy(NewYeildingIdleStrategy()))
+	p := NewEventProcessor(rb, rb.NewBarrier(), EventHandlerFunc(func(interface{}, int64, bool) error { return nil }))
+
+	p.Halt()
+	done := make(chan error)
+	go func() { done <- p.Run(context.Background()) }()
+	select {
+	case err := <-done:
+		assert.Nil(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run should honour Halt called before it")
+	}
+	assert.False(t, p.IsRunning())
+
+	// the halt is consumed, so the processor may be run again
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { done <- p.Run(ctx) }()
+	assert.Eventually(t, p.IsRunning, time.Second, time.Millisecond)
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+}
@@ -0,0 +1,411 @@
+package concurrent
+
+import (
+	"sync"
+	"time"
+)
+
+// TenantStats holds the statistics of a FairQueue tenant.
+type TenantStats struct {
+	// Depth is the number of the elements in the tenant sub-queue.
+	Depth int
+	// Enqueued is the number of the elements inserted into the sub-queue.
+	Enqueued uint64
+	// Dequeued is the number of the elements removed from the sub-queue.
+	Dequeued uint64
+	// Rejected is the number of the elements rejected because the sub-queue
+	// was full.
+	Rejected uint64
+}
+
+// FairQueue is a safe for concurrent use Queue implementation which keeps a
+// sub-queue per tenant and serves the tenants in weighted round-robin order:
+// a tenant with the weight w gets up to w elements retrieved in its turn. The
+// tenant of an element is defined by the key function.
+//
+// A tenant is created on its first element, or by SetWeight, and keeps its
+// weight and statistics while it has elements. A drained tenant releases its
+// sub-queue and is removed together with its weight and statistics once it
+// has stayed idle for the idle timeout, so the queue does not grow with the
+// number of the tenants ever seen.
+type FairQueue struct {
+	sync.RWMutex
+	key         func(e interface{}) interface{}
+	capacity    int
+	tenants     map[interface{}]*fairTenant
+	active      []*fairTenant
+	idle        []idleTenant
+	cur         int
+	count       int
+	idleTimeout time.Duration
+	clock       Clock
+}
+
+// fairTenant is the sub-queue of a tenant. The elements are kept in the ring
+// buf starting from the position head. The idle time is zero while the
+// tenant has elements.
+type fairTenant struct {
+	key    interface{}
+	buf    []interface{}
+	head   int
+	size   int
+	served int
+	weight int
+	stats  TenantStats
+	idle   time.Time
+}
+
+// idleTenant is an entry of the list of the tenants in the order they have
+// become idle. The entry is stale if the tenant has got elements since then.
+type idleTenant struct {
+	t    *fairTenant
+	idle time.Time
+}
+
+// defaultTenantIdleTimeout is the default time after which an idle tenant is
+// removed.
+const defaultTenantIdleTimeout = time.Minute
+
+// NewFairQueue returns pointer to a new FairQueue instance. The key function
+// returns the tenant of an element. The capacity limits the number of the
+// elements of each tenant; if it is not positive, the sub-queues are
+// unbounded.
+func NewFairQueue(key func(e interface{}) interface{}, capacity int) *FairQueue {
+	return &FairQueue{
+		key:         key,
+		capacity:    capacity,
+		tenants:     make(map[interface{}]*fairTenant),
+		idleTimeout: defaultTenantIdleTimeout,
+		clock:       NewSystemClock(),
+	}
+}
+
+// SetIdleTimeout sets the time after which a tenant without elements is
+// removed. If d is not positive, the tenants are removed as soon as they are
+// drained. The default timeout is one minute.
+func (q *FairQueue) SetIdleTimeout(d time.Duration) {
+	q.Lock()
+	defer q.Unlock()
+	q.idleTimeout = d
+	q.prune()
+}
+
+// SetWeight sets the weight of the tenant k. The default weight is 1. The
+// weight of a tenant without elements is kept for the idle timeout.
+func (q *FairQueue) SetWeight(k interface{}, w int) {
+	if w < 1 {
+		panic("weight must be positive")
+	}
+	q.Lock()
+	defer q.Unlock()
+	q.prune()
+	t, ok := q.tenants[k]
+	if !ok {
+		t = q.newTenant(k)
+		q.setIdle(t)
+	}
+	t.weight = w
+}
+
+// Weight returns the weight of the tenant k.
+func (q *FairQueue) Weight(k interface{}) int {
+	q.RLock()
+	defer q.RUnlock()
+	if t, ok := q.tenants[k]; ok {
+		return t.weight
+	}
+	return 1
+}
+
+// TenantStats returns the statistics of the tenant k and true, or false if
+// the tenant is unknown or has been removed after the idle timeout.
+func (q *FairQueue) TenantStats(k interface{}) (TenantStats, bool) {
+	q.RLock()
+	defer q.RUnlock()
+	t, ok := q.tenants[k]
+	if !ok {
+		return TenantStats{}, false
+	}
+	return t.stats, true
+}
+
+// Stats returns the statistics of all the tenants.
+func (q *FairQueue) Stats() map[interface{}]TenantStats {
+	q.RLock()
+	defer q.RUnlock()
+	r := make(map[interface{}]TenantStats, len(q.tenants))
+	for k, t := range q.tenants {
+		r[k] = t.stats
+	}
+	return r
+}
+
+// Tenants returns the number of the tenants which have elements.
+func (q *FairQueue) Tenants() int {
+	q.RLock()
+	defer q.RUnlock()
+	return len(q.active)
+}
+
+// Size implements Queue.Size
+func (q *FairQueue) Size() int {
+	q.RLock()
+	defer q.RUnlock()
+	return q.count
+}
+
+// Clear implements Queue.Clear. The tenants become idle keeping their
+// counters.
+func (q *FairQueue) Clear() {
+	q.Lock()
+	defer q.Unlock()
+	for _, t := range q.active {
+		t.buf, t.head, t.size, t.served = nil, 0, 0, 0
+		t.stats.Depth = 0
+		q.setIdle(t)
+	}
+	q.active = nil
+	q.cur = 0
+	q.count = 0
+	q.prune()
+}
+
+// Offer implements Queue.Offer
+func (q *FairQueue) Offer(e interface{}) {
+	q.TryOffer(e)
+}
+
+// TryOffer implements Queue.TryOffer. Returns false if the sub-queue of the
+// element tenant is full.
+func (q *FairQueue) TryOffer(e interface{}) bool {
+	k := q.key(e)
+	q.Lock()
+	defer q.Unlock()
+	return q.offer(k, e)
+}
+
+// Poll implements Queue.Poll
+func (q *FairQueue) Poll() interface{} {
+	q.Lock()
+	defer q.Unlock()
+	return q.poll()
+}
+
+// Peek implements Queue.Peek. Returns the element the next Poll retrieves.
+func (q *FairQueue) Peek() interface{} {
+	q.RLock()
+	defer q.RUnlock()
+	if len(q.active) == 0 {
+		return nil
+	}
+	return q.active[q.cur].peek()
+}
+
+// Range implements Queue.Range. The elements are visited tenant by tenant
+// starting from the tenant to be served next.
+func (q *FairQueue) Range(f func(e interface{}) bool) {
+	q.RLock()
+	defer q.RUnlock()
+	ok := true
+	for i := 0; i < len(q.active) && ok; i++ {
+		t := q.active[(q.cur+i)%len(q.active)]
+		for j := 0; j < t.size && ok; j++ {
+			ok = f(t.buf[(t.head+j)%len(t.buf)])
+		}
+	}
+}
+
+// ToSlice implements Queue.ToSlice. The elements are returned in Range order.
+func (q *FairQueue) ToSlice() []interface{} {
+	return q.ToSliceInto(nil)
+}
+
+// ToSliceInto implements Queue.ToSliceInto. The elements are copied in Range
+// order.
+func (q *FairQueue) ToSliceInto(dst []interface{}) []interface{} {
+	q.RLock()
+	n := q.count
+	q.RUnlock()
+	if dst == nil {
+		dst = make([]interface{}, 0, n)
+	}
+	dst = dst[:0]
+	q.Range(func(e interface{}) bool {
+		dst = append(dst, e)
+		return true
+	})
+	return dst
+}
+
+// Drain implements Queue.Drain. The elements are removed in the fair order.
+func (q *FairQueue) Drain(max int, f func(e interface{})) int {
+	q.Lock()
+	n := q.count
+	if max > 0 && max < n {
+		n = max
+	}
+	buf := make([]interface{}, n)
+	q.drainTo(buf)
+	q.Unlock()
+
+	for _, e := range buf {
+		f(e)
+	}
+	return n
+}
+
+// DrainTo implements Queue.DrainTo. The elements are removed in the fair
+// order.
+func (q *FairQueue) DrainTo(dst []interface{}) int {
+	q.Lock()
+	defer q.Unlock()
+	return q.drainTo(dst)
+}
+
+// OfferAll implements Queue.OfferAll
+func (q *FairQueue) OfferAll(es ...interface{}) int {
+	ks := make([]interface{}, len(es))
+	for i, e := range es {
+		ks[i] = q.key(e)
+	}
+	q.Lock()
+	defer q.Unlock()
+	n := 0
+	for i, e := range es {
+		if q.offer(ks[i], e) {
+			n++
+		}
+	}
+	return n
+}
+
+func (q *FairQueue) offer(k, e interface{}) bool {
+	q.prune()
+	t, ok := q.tenants[k]
+	if !ok {
+		t = q.newTenant(k)
+	}
+	if q.capacity > 0 && t.size >= q.capacity {
+		t.stats.Rejected++
+		return false
+	}
+	if t.size == 0 {
+		t.idle = time.Time{}
+		q.active = append(q.active, t)
+	}
+	t.push(e)
+	t.stats.Depth++
+	t.stats.Enqueued++
+	q.count++
+	return true
+}
+
+// poll removes the next element in the weighted round-robin order.
+func (q *FairQueue) poll() interface{} {
+	q.prune()
+	if len(q.active) == 0 {
+		return nil
+	}
+	t := q.active[q.cur]
+	e := t.pop()
+	t.stats.Depth--
+	t.stats.Dequeued++
+	q.count--
+
+	if t.size == 0 {
+		q.deactivate(t)
+		return e
+	}
+	if t.served++; t.served >= t.weight {
+		t.served = 0
+		q.cur = (q.cur + 1) % len(q.active)
+	}
+	return e
+}
+
+func (q *FairQueue) drainTo(dst []interface{}) int {
+	n := q.count
+	if len(dst) < n {
+		n = len(dst)
+	}
+	for i := 0; i < n; i++ {
+		dst[i] = q.poll()
+	}
+	return n
+}
+
+// newTenant creates the tenant k without elements.
+func (q *FairQueue) newTenant(k interface{}) *fairTenant {
+	t := &fairTenant{key: k, weight: 1}
+	q.tenants[k] = t
+	return t
+}
+
+// deactivate releases the sub-queue of the drained tenant t which is the
+// current one.
+func (q *FairQueue) deactivate(t *fairTenant) {
+	q.active = removeAt(q.active, q.cur)
+	if q.cur >= len(q.active) {
+		q.cur = 0
+	}
+	t.buf, t.head, t.served = nil, 0, 0
+	q.setIdle(t)
+}
+
+// setIdle appends the tenant t without elements to the idle list.
+func (q *FairQueue) setIdle(t *fairTenant) {
+	t.idle = q.clock.Now()
+	q.idle = append(q.idle, idleTenant{t: t, idle: t.idle})
+	q.prune()
+}
+
+// prune removes the tenants which have stayed idle for the idle timeout.
+func (q *FairQueue) prune() {
+	if len(q.idle) == 0 {
+		return
+	}
+	now := q.clock.Now()
+	n := 0
+	for _, it := range q.idle {
+		if it.t.idle.Equal(it.idle) && now.Sub(it.idle) < q.idleTimeout {
+			break
+		}
+		if it.t.idle.Equal(it.idle) && q.tenants[it.t.key] == it.t {
+			delete(q.tenants, it.t.key)
+		}
+		n++
+	}
+	for i := 0; i < n; i++ {
+		q.idle[i] = idleTenant{}
+	}
+	q.idle = q.idle[n:]
+}
+
+// push appends the element e to the sub-queue growing the ring if it is full.
+func (t *fairTenant) push(e interface{}) {
+	if len(t.buf) == 0 {
+		t.buf = make([]interface{}, 2)
+	} else if t.size == len(t.buf) {
+		buf := make([]interface{}, len(t.buf)*2)
+		n := copy(buf, t.buf[t.head:])
+		copy(buf[n:], t.buf[:t.head])
+		t.buf = buf
+		t.head = 0
+	}
+	t.buf[(t.head+t.size)%len(t.buf)] = e
+	t.size++
+}
+
+// pop removes the head of the sub-queue, which must not be empty.
+func (t *fairTenant) pop() interface{} {
+	e := t.buf[t.head]
+	t.buf[t.head] = nil
+	t.head = (t.head + 1) % len(t.buf)
+	t.size--
+	return e
+}
+
+// peek returns the head of the sub-queue, which must not be empty.
+func (t *fairTenant) peek() interface{} {
+	return t.buf[t.head]
+}
@@ -0,0 +1,233 @@
+package concurrent
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type job struct {
+	tenant string
+	id     int
+}
+
+func jobTenant(e interface{}) interface{} {
+	return e.(job).tenant
+}
+
+func TestFairQueueRoundRobin(t *testing.T) {
+	q := NewFairQueue(jobTenant, 0)
+	var _ Queue = q
+
+	assert.Nil(t, q.Poll())
+	assert.Nil(t, q.Peek())
+
+	// the noisy tenant comes first
+	for i := 0; i < 5; i++ {
+		q.Offer(job{"noisy", i})
+	}
+	q.Offer(job{"a", 0})
+	q.Offer(job{"b", 0})
+	q.Offer(job{"b", 1})
+	assert.Equal(t, 8, q.Size())
+	assert.Equal(t, 3, q.Tenants())
+
+	var got []string
+	for e := q.Poll(); e != nil; e = q.Poll() {
+		j := e.(job)
+		got = append(got, fmt.Sprintf("%s%d", j.tenant, j.id))
+	}
+	assert.Equal(t, []string{"noisy0", "a0", "b0", "noisy1", "b1", "noisy2", "noisy3", "noisy4"}, got)
+	assert.Equal(t, 0, q.Size())
+	assert.Equal(t, 0, q.Tenants(), "Drained sub-queues should be removed")
+}
+
+func TestFairQueueWeights(t *testing.T) {
+	q := NewFairQueue(jobTenant, 0)
+	q.SetWeight("a", 3)
+	assert.Equal(t, 3, q.Weight("a"))
+	assert.Equal(t, 1, q.Weight("b"))
+	assert.Panics(t, func() { q.SetWeight("a", 0) })
+
+	for i := 0; i < 6; i++ {
+		q.Offer(job{"a", i})
+		q.Offer(job{"b", i})
+	}
+
+	assert.Equal(t, job{"a", 0}, q.Peek())
+	dst := make([]interface{}, 8)
+	assert.Equal(t, 8, q.DrainTo(dst))
+	var tenants string
+	for _, e := range dst {
+		tenants += e.(job).tenant
+	}
+	assert.Equal(t, "aaabaaab", tenants)
+	assert.Equal(t, job{"b", 2}, q.Peek())
+
+	q.SetWeight("a", 1)
+	assert.Equal(t, 1, q.Weight("a"))
+}
+
+func TestFairQueueBoundsAndStats(t *testing.T) {
+	q := NewFairQueue(jobTenant, 2)
+
+	assert.True(t, q.TryOffer(job{"a", 0}))
+	assert.True(t, q.TryOffer(job{"a", 1}))
+	assert.False(t, q.TryOffer(job{"a", 2}), "Should reject above the tenant capacity")
+	assert.Equal(t, 1, q.OfferAll(job{"a", 3}, job{"b", 0}))
+
+	s, ok := q.TenantStats("a")
+	assert.True(t, ok)
+	assert.Equal(t, TenantStats{Depth: 2, Enqueued: 2, Rejected: 2}, s)
+
+	assert.Equal(t, job{"a", 0}, q.Poll())
+	stats := q.Stats()
+	assert.Equal(t, 2, len(stats))
+	assert.Equal(t, TenantStats{Depth: 1, Enqueued: 2, Dequeued: 1, Rejected: 2}, stats["a"])
+	assert.Equal(t, TenantStats{Depth: 1, Enqueued: 1}, stats["b"])
+
+	assert.Equal(t, job{"b", 0}, q.Poll())
+	assert.Equal(t, 1, q.Tenants(), "Drained sub-queue should be released")
+	s, ok = q.TenantStats("b")
+	assert.True(t, ok, "Stats of a caught-up tenant should be kept")
+	assert.Equal(t, TenantStats{Enqueued: 1, Dequeued: 1}, s)
+
+	q.Offer(job{"b", 1})
+	s, _ = q.TenantStats("b")
+	assert.Equal(t, TenantStats{Depth: 1, Enqueued: 2, Dequeued: 1}, s)
+
+	q.Clear()
+	assert.Equal(t, 0, q.Size())
+	assert.Equal(t, 0, q.Tenants())
+	s, _ = q.TenantStats("a")
+	assert.Equal(t, TenantStats{Enqueued: 2, Dequeued: 1, Rejected: 2}, s)
+}
+
+func TestFairQueueIdleTimeout(t *testing.T) {
+	clock := newFakeClock()
+	q := NewFairQueue(jobTenant, 0)
+	q.clock = clock
+
+	q.SetWeight("w", 3)
+	q.OfferAll(job{"a", 0}, job{"b", 0})
+	q.Poll()
+	assert.Equal(t, 3, len(q.Stats()))
+
+	// a is idle since now, b still has an element
+	clock.Advance(30 * time.Second)
+	q.Offer(job{"c", 0})
+	_, ok := q.TenantStats("a")
+	assert.True(t, ok)
+
+	clock.Advance(30 * time.Second)
+	q.Poll()
+	_, ok = q.TenantStats("a")
+	assert.False(t, ok, "Idle tenant should be removed after the timeout")
+	assert.Equal(t, 1, q.Weight("w"), "Weight should be removed with the idle tenant")
+	_, ok = q.TenantStats("b")
+	assert.True(t, ok, "Tenant drained just now should be kept")
+
+	// a tenant which gets elements again is not removed by its old entry
+	q.Offer(job{"b", 1})
+	clock.Advance(time.Minute)
+	q.Poll()
+	q.Poll()
+	s, ok := q.TenantStats("b")
+	assert.True(t, ok)
+	assert.Equal(t, TenantStats{Enqueued: 2, Dequeued: 2}, s)
+	assert.Equal(t, []interface{}{"b", "c"}, sortedKeys(q.Stats()))
+
+	q.SetIdleTimeout(0)
+	assert.Equal(t, 0, len(q.Stats()), "Zero timeout should remove drained tenants")
+	q.Offer(job{"a", 1})
+	q.Poll()
+	assert.Equal(t, 0, len(q.Stats()))
+}
+
+func sortedKeys(m map[interface{}]TenantStats) []interface{} {
+	ks := make([]string, 0, len(m))
+	for k := range m {
+		ks = append(ks, k.(string))
+	}
+	sort.Strings(ks)
+	r := make([]interface{}, len(ks))
+	for i, k := range ks {
+		r[i] = k
+	}
+	return r
+}
+
+func TestFairQueueRingGrowth(t *testing.T) {
+	q := NewFairQueue(jobTenant, 0)
+	// wrap the ring of the sub-queue before it grows
+	q.OfferAll(job{"a", 0}, job{"a", 1})
+	q.Poll()
+	for i := 2; i < 10; i++ {
+		assert.True(t, q.TryOffer(job{"a", i}))
+	}
+	for i := 1; i < 10; i++ {
+		assert.Equal(t, job{"a", i}, q.Poll())
+	}
+	assert.Nil(t, q.Poll())
+	assert.Equal(t, 0, q.Tenants())
+}
+
+func TestFairQueueRange(t *testing.T) {
+	q := NewFairQueue(jobTenant, 0)
+	q.OfferAll(job{"a", 0}, job{"b", 0}, job{"a", 1})
+	assert.Equal(t, []interface{}{job{"a", 0}, job{"a", 1}, job{"b", 0}}, q.ToSlice())
+
+	q.Poll()
+	assert.Equal(t, []interface{}{job{"b", 0}, job{"a", 1}}, q.ToSlice(), "Range should start from the next tenant")
+
+	n := 0
+	q.Range(func(interface{}) bool {
+		n++
+		return false
+	})
+	assert.Equal(t, 1, n)
+
+	var got []interface{}
+	assert.Equal(t, 2, q.Drain(0, func(e interface{}) { got = append(got, e) }))
+	assert.Equal(t, []interface{}{job{"b", 0}, job{"a", 1}}, got)
+}
+
+func TestFairQueueConcurrent(t *testing.T) {
+	const tenants = 4
+	const n = 1000
+
+	q := NewFairQueue(jobTenant, 0)
+	var wg sync.WaitGroup
+	for i := 0; i < tenants; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < n; j++ {
+				q.Offer(job{fmt.Sprint(i), j})
+			}
+		}(i)
+	}
+
+	last := make(map[string]int)
+	polled := 0
+	for polled < tenants*n {
+		e := q.Poll()
+		if e == nil {
+			runtime.Gosched()
+			continue
+		}
+		j := e.(job)
+		if p, ok := last[j.tenant]; ok && j.id != p+1 {
+			t.Fatalf("tenant %s: %d after %d", j.tenant, j.id, p)
+		}
+		last[j.tenant] = j.id
+		polled++
+	}
+	wg.Wait()
+	assert.Equal(t, 0, q.Size())
+}
@@ -1,9 +1,11 @@
 module github.com/vontikov/go-concurrent
 
-go 1.14
+go 1.24
+
+require github.com/stretchr/testify v1.6.1
 
 require (
-	github.com/stretchr/testify v1.6.1
-	golang.org/x/lint v0.0.0-20200302205851-738671d3881b // indirect
-	golang.org/x/tools v0.0.0-20201017001424-6003fad69a88 // indirect
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
 )
@@ -0,0 +1,283 @@
+package concurrent
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// handoffQueue matches producers with consumers. It is the common part of
+// SynchronousQueue and TransferQueue.
+//
+// The producers list holds the elements waiting for consumers in insertion
+// order; an element inserted by a producer which waits for the handoff has a
+// done channel which is closed once the element is taken. The consumers list holds the consumers
+// waiting for elements; each of them receives its element through a channel.
+// At most one of the lists is not empty at a time.
+type handoffQueue struct {
+	sync.RWMutex
+	producers []*handoffProducer
+	consumers []*handoffConsumer
+	fair      bool
+	closed    bool
+}
+
+type handoffProducer struct {
+	e    interface{}
+	done chan struct{}
+}
+
+type handoffConsumer struct {
+	ch chan interface{}
+}
+
+// Poll implements Queue.Poll
+func (q *handoffQueue) Poll() interface{} {
+	q.Lock()
+	defer q.Unlock()
+	if len(q.producers) == 0 {
+		return nil
+	}
+	return q.take()
+}
+
+// Drain implements Queue.Drain
+func (q *handoffQueue) Drain(max int, f func(e interface{})) int {
+	q.Lock()
+	n := len(q.producers)
+	if max > 0 && max < n {
+		n = max
+	}
+	buf := make([]interface{}, n)
+	q.drainTo(buf)
+	q.Unlock()
+
+	for _, e := range buf {
+		f(e)
+	}
+	return n
+}
+
+// DrainTo implements Queue.DrainTo
+func (q *handoffQueue) DrainTo(dst []interface{}) int {
+	q.Lock()
+	defer q.Unlock()
+	return q.drainTo(dst)
+}
+
+// Take implements BlockingQueue.Take
+func (q *handoffQueue) Take(ctx context.Context) (interface{}, error) {
+	q.Lock()
+	if len(q.producers) > 0 {
+		e := q.take()
+		q.Unlock()
+		return e, nil
+	}
+	if q.closed {
+		q.Unlock()
+		return nil, ErrClosed
+	}
+	c := &handoffConsumer{ch: make(chan interface{}, 1)}
+	q.consumers = append(q.consumers, c)
+	q.Unlock()
+
+	select {
+	case e, ok := <-c.ch:
+		if !ok {
+			return nil, ErrClosed
+		}
+		return e, nil
+	case <-ctx.Done():
+	}
+
+	q.Lock()
+	defer q.Unlock()
+	if q.removeConsumer(c) {
+		return nil, ctx.Err()
+	}
+	// the element has been handed off before the consumer is removed
+	e, ok := <-c.ch
+	if !ok {
+		return nil, ErrClosed
+	}
+	return e, nil
+}
+
+// PollTimeout implements BlockingQueue.PollTimeout
+func (q *handoffQueue) PollTimeout(d time.Duration) (interface{}, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	e, err := q.Take(ctx)
+	return e, err == nil
+}
+
+// Close implements BlockingQueue.Close. The waiting consumers get ErrClosed;
+// the waiting producers keep waiting for consumers.
+func (q *handoffQueue) Close() {
+	q.Lock()
+	defer q.Unlock()
+	q.closed = true
+	for _, c := range q.consumers {
+		close(c.ch)
+	}
+	q.consumers = nil
+}
+
+// WaitingConsumers returns the number of consumers waiting for elements.
+func (q *handoffQueue) WaitingConsumers() int {
+	q.RLock()
+	defer q.RUnlock()
+	return len(q.consumers)
+}
+
+// transfer hands the element e off to a waiting consumer. If there is none
+// and wait is false, the element is appended to the producers list. If wait
+// is true, it waits until a consumer takes the element or ctx is done.
+func (q *handoffQueue) transfer(ctx context.Context, e interface{}, wait bool) error {
+	q.Lock()
+	if q.closed {
+		q.Unlock()
+		return ErrClosed
+	}
+	if q.handoff(e) {
+		q.Unlock()
+		return nil
+	}
+	p := &handoffProducer{e: e}
+	if wait {
+		p.done = make(chan struct{})
+	}
+	q.producers = append(q.producers, p)
+	q.Unlock()
+	if !wait {
+		return nil
+	}
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	q.Lock()
+	defer q.Unlock()
+	if q.removeProducer(p) {
+		return ctx.Err()
+	}
+	// the element has been taken before the producer is removed
+	return nil
+}
+
+// tryTransfer hands the element e off to a waiting consumer. Returns false if
+// there is none or the queue is closed.
+func (q *handoffQueue) tryTransfer(e interface{}) bool {
+	q.Lock()
+	defer q.Unlock()
+	return !q.closed && q.handoff(e)
+}
+
+// handoff passes the element e to a waiting consumer if there is one.
+func (q *handoffQueue) handoff(e interface{}) bool {
+	if len(q.consumers) == 0 {
+		return false
+	}
+	i := 0
+	if !q.fair {
+		i = len(q.consumers) - 1
+	}
+	c := q.consumers[i]
+	q.consumers = removeAt(q.consumers, i)
+	c.ch <- e
+	return true
+}
+
+// take removes the next element from the producers list and releases its
+// producer. The list must not be empty.
+func (q *handoffQueue) take() interface{} {
+	i := q.nextProducer()
+	p := q.producers[i]
+	q.producers = removeAt(q.producers, i)
+	if p.done != nil {
+		close(p.done)
+	}
+	return p.e
+}
+
+// peekHead returns the next producer and its element.
+func (q *handoffQueue) peekHead() (interface{}, interface{}) {
+	q.RLock()
+	defer q.RUnlock()
+	if len(q.producers) == 0 {
+		return nil, nil
+	}
+	p := q.producers[q.nextProducer()]
+	return p, p.e
+}
+
+// removeHead removes the element of the producer h and releases the producer.
+func (q *handoffQueue) removeHead(h interface{}) bool {
+	q.Lock()
+	defer q.Unlock()
+	p := h.(*handoffProducer)
+	if !q.removeProducer(p) {
+		return false
+	}
+	if p.done != nil {
+		close(p.done)
+	}
+	return true
+}
+
+func (q *handoffQueue) drainTo(dst []interface{}) int {
+	n := len(q.producers)
+	if len(dst) < n {
+		n = len(dst)
+	}
+	for i := 0; i < n; i++ {
+		dst[i] = q.take()
+	}
+	return n
+}
+
+// nextProducer returns the index of the next element of the producers list,
+// which must not be empty. The elements are taken in insertion order, but if
+// the queue is not fair and the oldest element is being transferred, the most
+// recent waiting producer goes first. The offered elements are always taken
+// in FIFO order.
+func (q *handoffQueue) nextProducer() int {
+	if q.fair || q.producers[0].done == nil {
+		return 0
+	}
+	i := len(q.producers) - 1
+	for q.producers[i].done == nil {
+		i--
+	}
+	return i
+}
+
+func (q *handoffQueue) removeProducer(p *handoffProducer) bool {
+	for i, o := range q.producers {
+		if o == p {
+			q.producers = removeAt(q.producers, i)
+			return true
+		}
+	}
+	return false
+}
+
+func (q *handoffQueue) removeConsumer(c *handoffConsumer) bool {
+	for i, o := range q.consumers {
+		if o == c {
+			q.consumers = removeAt(q.consumers, i)
+			return true
+		}
+	}
+	return false
+}
+
+// removeAt removes the element at the position i of the slice s.
+func removeAt[T any](s []*T, i int) []*T {
+	copy(s[i:], s[i+1:])
+	s[len(s)-1] = nil
+	return s[:len(s)-1]
+}
@@ -0,0 +1,49 @@
+package concurrent
+
+// snapshotIterator is an Iterator over a copy of the collection elements.
+type snapshotIterator struct {
+	src      Versioned
+	data     []interface{}
+	version  uint64
+	failFast bool
+	i        int
+	err      error
+}
+
+// newSnapshotIterator returns a new iterator over data which is a snapshot of
+// the collection src taken at the version.
+func newSnapshotIterator(src Versioned, data []interface{}, version uint64, failFast bool) Iterator {
+	return &snapshotIterator{
+		src:      src,
+		data:     data,
+		version:  version,
+		failFast: failFast,
+		i:        -1,
+	}
+}
+
+// Next implements Iterator.Next
+func (it *snapshotIterator) Next() bool {
+	if it.err != nil || it.i >= len(it.data) {
+		return false
+	}
+	if it.failFast && it.src.Version() != it.version {
+		it.err = ErrConcurrentModification
+		return false
+	}
+	it.i++
+	return it.i < len(it.data)
+}
+
+// Value implements Iterator.Value
+func (it *snapshotIterator) Value() interface{} {
+	if it.i < 0 || it.i >= len(it.data) {
+		return nil
+	}
+	return it.data[it.i]
+}
+
+// Err implements Iterator.Err
+func (it *snapshotIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,222 @@
+package concurrent
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// MPMCQueue is a lock-free bounded Queue implementation which is safe for
+// concurrent use by multiple producers and consumers. It is based on the
+// array of slots with sequence numbers proposed by Dmitry Vyukov.
+//
+// Size, Peek, Range and the methods built on them are weakly consistent: they
+// reflect some state of the queue at or since their call.
+//
+// The elements must not be nil.
+type MPMCQueue struct {
+	_     cacheLinePad
+	tail  atomic.Int64
+	_     cacheLinePad
+	head  atomic.Int64
+	_     cacheLinePad
+	slots []mpmcSlot
+	mask  int64
+}
+
+// mpmcSlot is a queue cell. The sequence tells the producer at position p
+// that the slot is free when it equals p, and tells the consumer at position
+// p that the slot is full when it equals p+1. The element is published by the
+// sequence store, so it is a plain field.
+//
+// The consumer and Range take the full slot by negating its sequence before
+// they access the element, so they do not access it at the same time.
+type mpmcSlot struct {
+	seq atomic.Int64
+	e   interface{}
+}
+
+// NewMPMCQueue returns pointer to a new MPMCQueue instance. The capacity must
+// be power of 2.
+func NewMPMCQueue(capacity int) *MPMCQueue {
+	checkPowerOfTwo(capacity)
+	q := &MPMCQueue{
+		slots: make([]mpmcSlot, capacity),
+		mask:  int64(capacity - 1),
+	}
+	for i := range q.slots {
+		q.slots[i].seq.Store(int64(i))
+	}
+	return q
+}
+
+// Size implements Queue.Size
+func (q *MPMCQueue) Size() int {
+	h := q.head.Load()
+	n := q.tail.Load() - h
+	switch {
+	case n < 0:
+		return 0
+	case n > int64(len(q.slots)):
+		return len(q.slots)
+	}
+	return int(n)
+}
+
+// Capacity returns the maximum number of the elements in the queue.
+func (q *MPMCQueue) Capacity() int {
+	return len(q.slots)
+}
+
+// Clear implements Queue.Clear
+func (q *MPMCQueue) Clear() {
+	for q.Poll() != nil {
+	}
+}
+
+// Offer implements Queue.Offer
+func (q *MPMCQueue) Offer(e interface{}) {
+	q.TryOffer(e)
+}
+
+// TryOffer implements Queue.TryOffer. Returns false if the queue is full.
+func (q *MPMCQueue) TryOffer(e interface{}) bool {
+	pos := q.tail.Load()
+	for {
+		s := &q.slots[pos&q.mask]
+		d := s.load() - pos
+		switch {
+		case d == 0:
+			if q.tail.CompareAndSwap(pos, pos+1) {
+				s.e = e
+				s.seq.Store(pos + 1)
+				return true
+			}
+			pos = q.tail.Load()
+		case d < 0:
+			return false
+		default:
+			pos = q.tail.Load()
+		}
+	}
+}
+
+// Poll implements Queue.Poll
+func (q *MPMCQueue) Poll() interface{} {
+	pos := q.head.Load()
+	for {
+		s := &q.slots[pos&q.mask]
+		d := s.load() - (pos + 1)
+		switch {
+		case d == 0:
+			if q.head.CompareAndSwap(pos, pos+1) {
+				for !s.seq.CompareAndSwap(pos+1, -(pos + 1)) {
+					// Range is reading the element
+					runtime.Gosched()
+				}
+				e := s.e
+				s.e = nil
+				s.seq.Store(pos + q.mask + 1)
+				return e
+			}
+			pos = q.head.Load()
+		case d < 0:
+			return nil
+		default:
+			pos = q.head.Load()
+		}
+	}
+}
+
+// Peek implements Queue.Peek
+func (q *MPMCQueue) Peek() interface{} {
+	var r interface{}
+	q.Range(func(e interface{}) bool {
+		r = e
+		return false
+	})
+	return r
+}
+
+// Range implements Queue.Range
+func (q *MPMCQueue) Range(f func(e interface{}) bool) {
+	for pos, t := q.head.Load(), q.tail.Load(); pos < t; pos++ {
+		s := &q.slots[pos&q.mask]
+		if !s.seq.CompareAndSwap(pos+1, -(pos + 1)) {
+			// the element has been consumed or is not published yet
+			if pos < q.head.Load() {
+				continue
+			}
+			return
+		}
+		e := s.e
+		s.seq.Store(pos + 1)
+		if !f(e) {
+			return
+		}
+	}
+}
+
+// ToSlice implements Queue.ToSlice
+func (q *MPMCQueue) ToSlice() []interface{} {
+	return q.ToSliceInto(nil)
+}
+
+// ToSliceInto implements Queue.ToSliceInto
+func (q *MPMCQueue) ToSliceInto(dst []interface{}) []interface{} {
+	if dst == nil {
+		dst = make([]interface{}, 0, q.Size())
+	}
+	dst = dst[:0]
+	q.Range(func(e interface{}) bool {
+		dst = append(dst, e)
+		return true
+	})
+	return dst
+}
+
+// Drain implements Queue.Drain
+func (q *MPMCQueue) Drain(max int, f func(e interface{})) int {
+	n := 0
+	for max <= 0 || n < max {
+		e := q.Poll()
+		if e == nil {
+			break
+		}
+		f(e)
+		n++
+	}
+	return n
+}
+
+// DrainTo implements Queue.DrainTo
+func (q *MPMCQueue) DrainTo(dst []interface{}) int {
+	for i := range dst {
+		e := q.Poll()
+		if e == nil {
+			return i
+		}
+		dst[i] = e
+	}
+	return len(dst)
+}
+
+// OfferAll implements Queue.OfferAll. It stops at the first element the
+// queue has no room for.
+func (q *MPMCQueue) OfferAll(es ...interface{}) int {
+	for i, e := range es {
+		if !q.TryOffer(e) {
+			return i
+		}
+	}
+	return len(es)
+}
+
+// load returns the sequence of the slot ignoring the mark of the consumer or
+// Range accessing the element.
+func (s *mpmcSlot) load() int64 {
+	seq := s.seq.Load()
+	if seq < 0 {
+		return -seq
+	}
+	return seq
+}
@@ -0,0 +1,78 @@
+package concurrent
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+)
+
+const benchQueueCapacity = 1024
+
+func benchmarkQueue(b *testing.B, offer func(e interface{}) bool, poll func() interface{}) {
+	const pairs = 4
+
+	n := b.N / pairs
+	var wg sync.WaitGroup
+	b.ResetTimer()
+	for p := 0; p < pairs; p++ {
+		wg.Add(2)
+		go func() {
+			for i := 0; i < n; i++ {
+				for !offer(i) {
+					runtime.Gosched()
+				}
+			}
+			wg.Done()
+		}()
+		go func() {
+			for i := 0; i < n; i++ {
+				for poll() == nil {
+					runtime.Gosched()
+				}
+			}
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkMPMCQueue(b *testing.B) {
+	q := NewMPMCQueue(benchQueueCapacity)
+	benchmarkQueue(b, q.TryOffer, q.Poll)
+}
+
+func BenchmarkMPMCQueueOfferPoll(b *testing.B) {
+	q := NewMPMCQueue(benchQueueCapacity)
+	var e interface{} = struct{}{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		q.Offer(e)
+		q.Poll()
+	}
+}
+
+func BenchmarkMPMCSynchronizedRingQueue(b *testing.B) {
+	q := NewBoundedSynchronizedRingQueue(benchQueueCapacity, OverflowReject)
+	benchmarkQueue(b, q.TryOffer, q.Poll)
+}
+
+func BenchmarkMPMCChannel(b *testing.B) {
+	ch := make(chan interface{}, benchQueueCapacity)
+	offer := func(e interface{}) bool {
+		select {
+		case ch <- e:
+			return true
+		default:
+			return false
+		}
+	}
+	poll := func() interface{} {
+		select {
+		case e := <-ch:
+			return e
+		default:
+			return nil
+		}
+	}
+	benchmarkQueue(b, offer, poll)
+}
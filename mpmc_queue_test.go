@@ -0,0 +1,137 @@
+package concurrent
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMPMCQueueOfferPoll(t *testing.T) {
+	const c = 4
+
+	q := NewMPMCQueue(c)
+	assert.Equal(t, c, q.Capacity())
+	assert.Nil(t, q.Poll())
+	assert.Nil(t, q.Peek())
+
+	for r := 0; r < 3; r++ {
+		for i := 0; i < c; i++ {
+			assert.True(t, q.TryOffer(i))
+		}
+		assert.False(t, q.TryOffer(c))
+		assert.Equal(t, c, q.Size())
+		assert.Equal(t, []interface{}{0, 1, 2, 3}, q.ToSlice())
+
+		for i := 0; i < c; i++ {
+			assert.Equal(t, i, q.Peek())
+			assert.Equal(t, i, q.Poll())
+		}
+		assert.Nil(t, q.Poll())
+		assert.Equal(t, 0, q.Size())
+	}
+
+	assert.Panics(t, func() { NewMPMCQueue(3) })
+}
+
+func TestMPMCQueueBatch(t *testing.T) {
+	q := NewMPMCQueue(4)
+
+	assert.Equal(t, 4, q.OfferAll(0, 1, 2, 3, 4))
+	dst := make([]interface{}, 3)
+	assert.Equal(t, 3, q.DrainTo(dst))
+	assert.Equal(t, []interface{}{0, 1, 2}, dst)
+
+	assert.Equal(t, 3, q.OfferAll(4, 5, 6))
+	var drained []interface{}
+	assert.Equal(t, 2, q.Drain(2, func(e interface{}) { drained = append(drained, e) }))
+	assert.Equal(t, []interface{}{3, 4}, drained)
+	assert.Equal(t, 2, q.Size())
+
+	q.Clear()
+	assert.Equal(t, 0, q.Size())
+	assert.Equal(t, 0, q.Drain(0, func(interface{}) {}))
+}
+
+func TestMPMCQueueConcurrent(t *testing.T) {
+	const producers = 4
+	const consumers = 4
+	const n = 10000
+
+	q := NewMPMCQueue(64)
+
+	type item struct{ p, i int }
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				for !q.TryOffer(item{p, i}) {
+					runtime.Gosched()
+				}
+			}
+		}(p)
+	}
+
+	var consumed int64
+	var mu sync.Mutex
+	seen := make([][]bool, producers)
+	for p := range seen {
+		seen[p] = make([]bool, n)
+	}
+	var cwg sync.WaitGroup
+	for c := 0; c < consumers; c++ {
+		cwg.Add(1)
+		go func() {
+			defer cwg.Done()
+			last := make([]int, producers)
+			for p := range last {
+				last[p] = -1
+			}
+			for atomic.LoadInt64(&consumed) < producers*n {
+				e := q.Poll()
+				if e == nil {
+					runtime.Gosched()
+					continue
+				}
+				it := e.(item)
+				if it.i <= last[it.p] {
+					t.Errorf("out of order: %d after %d", it.i, last[it.p])
+				}
+				last[it.p] = it.i
+				mu.Lock()
+				if seen[it.p][it.i] {
+					t.Errorf("duplicate: %v", it)
+				}
+				seen[it.p][it.i] = true
+				mu.Unlock()
+				atomic.AddInt64(&consumed, 1)
+			}
+		}()
+	}
+
+	// weakly consistent observers must not interfere
+	stop := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				q.Peek()
+				q.ToSlice()
+				runtime.Gosched()
+			}
+		}
+	}()
+
+	wg.Wait()
+	cwg.Wait()
+	close(stop)
+	assert.Equal(t, int64(producers*n), consumed)
+	assert.Equal(t, 0, q.Size())
+}
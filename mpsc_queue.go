@@ -0,0 +1,163 @@
+package concurrent
+
+import (
+	"sync/atomic"
+)
+
+// MPSCQueue is an unbounded lock-free Queue implementation which is safe for
+// concurrent use by multiple producers and a single consumer. It is based on
+// the node-based queue proposed by Dmitry Vyukov. Producers never block, Poll
+// is wait-free.
+//
+// Offer, TryOffer and OfferAll may be called by any goroutine. The other
+// methods, except Size, must be called by the consumer goroutine only. A Poll
+// which runs concurrently with an Offer may observe the queue as empty until
+// the Offer completes.
+//
+// The elements must not be nil.
+type MPSCQueue struct {
+	_ cacheLinePad
+	// head is the most recently offered node
+	head atomic.Pointer[mpscNode]
+	_    cacheLinePad
+	// tail is the stub node preceding the next node to poll
+	tail *mpscNode
+	size atomic.Int64
+}
+
+type mpscNode struct {
+	next atomic.Pointer[mpscNode]
+	e    interface{}
+}
+
+// NewMPSCQueue returns pointer to a new MPSCQueue instance.
+func NewMPSCQueue() *MPSCQueue {
+	stub := &mpscNode{}
+	q := &MPSCQueue{tail: stub}
+	q.head.Store(stub)
+	return q
+}
+
+// Size implements Queue.Size
+func (q *MPSCQueue) Size() int {
+	return int(q.size.Load())
+}
+
+// Clear implements Queue.Clear
+func (q *MPSCQueue) Clear() {
+	for q.Poll() != nil {
+	}
+}
+
+// Offer implements Queue.Offer
+func (q *MPSCQueue) Offer(e interface{}) {
+	q.TryOffer(e)
+}
+
+// TryOffer implements Queue.TryOffer. It always returns true.
+func (q *MPSCQueue) TryOffer(e interface{}) bool {
+	n := &mpscNode{e: e}
+	q.size.Add(1)
+	q.link(n, n)
+	return true
+}
+
+// OfferAll implements Queue.OfferAll. The elements are linked together and
+// published at once, so they are not interleaved with other producers'
+// elements.
+func (q *MPSCQueue) OfferAll(es ...interface{}) int {
+	if len(es) == 0 {
+		return 0
+	}
+	first := &mpscNode{e: es[0]}
+	last := first
+	for _, e := range es[1:] {
+		n := &mpscNode{e: e}
+		last.next.Store(n)
+		last = n
+	}
+	q.size.Add(int64(len(es)))
+	q.link(first, last)
+	return len(es)
+}
+
+// Poll implements Queue.Poll
+func (q *MPSCQueue) Poll() interface{} {
+	next := q.tail.next.Load()
+	if next == nil {
+		return nil
+	}
+	e := next.e
+	next.e = nil
+	q.tail = next
+	q.size.Add(-1)
+	return e
+}
+
+// Peek implements Queue.Peek
+func (q *MPSCQueue) Peek() interface{} {
+	next := q.tail.next.Load()
+	if next == nil {
+		return nil
+	}
+	return next.e
+}
+
+// Range implements Queue.Range
+func (q *MPSCQueue) Range(f func(e interface{}) bool) {
+	for n := q.tail.next.Load(); n != nil; n = n.next.Load() {
+		if !f(n.e) {
+			return
+		}
+	}
+}
+
+// ToSlice implements Queue.ToSlice
+func (q *MPSCQueue) ToSlice() []interface{} {
+	return q.ToSliceInto(nil)
+}
+
+// ToSliceInto implements Queue.ToSliceInto
+func (q *MPSCQueue) ToSliceInto(dst []interface{}) []interface{} {
+	if dst == nil {
+		dst = make([]interface{}, 0, q.Size())
+	}
+	dst = dst[:0]
+	q.Range(func(e interface{}) bool {
+		dst = append(dst, e)
+		return true
+	})
+	return dst
+}
+
+// Drain implements Queue.Drain
+func (q *MPSCQueue) Drain(max int, f func(e interface{})) int {
+	n := 0
+	for max <= 0 || n < max {
+		e := q.Poll()
+		if e == nil {
+			break
+		}
+		f(e)
+		n++
+	}
+	return n
+}
+
+// DrainTo implements Queue.DrainTo
+func (q *MPSCQueue) DrainTo(dst []interface{}) int {
+	for i := range dst {
+		e := q.Poll()
+		if e == nil {
+			return i
+		}
+		dst[i] = e
+	}
+	return len(dst)
+}
+
+// link appends the chain of nodes from first to last to the queue.
+func (q *MPSCQueue) link(first, last *mpscNode) {
+	prev := q.head.Swap(last)
+	prev.next.Store(first)
+}
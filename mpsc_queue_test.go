@@ -0,0 +1,135 @@
+package concurrent
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMPSCQueueOfferPoll(t *testing.T) {
+	const n = 100
+
+	q := NewMPSCQueue()
+	assert.Nil(t, q.Poll())
+	assert.Nil(t, q.Peek())
+
+	for i := 0; i < n; i++ {
+		assert.True(t, q.TryOffer(i))
+	}
+	assert.Equal(t, n, q.Size())
+
+	s := q.ToSlice()
+	for i := 0; i < n; i++ {
+		assert.Equal(t, i, s[i])
+	}
+
+	for i := 0; i < n; i++ {
+		assert.Equal(t, i, q.Peek())
+		assert.Equal(t, i, q.Poll())
+	}
+	assert.Nil(t, q.Poll())
+	assert.Equal(t, 0, q.Size())
+}
+
+func TestMPSCQueueBatch(t *testing.T) {
+	q := NewMPSCQueue()
+
+	assert.Equal(t, 0, q.OfferAll())
+	assert.Equal(t, 5, q.OfferAll(0, 1, 2, 3, 4))
+	assert.Equal(t, 5, q.Size())
+
+	dst := make([]interface{}, 3)
+	assert.Equal(t, 3, q.DrainTo(dst))
+	assert.Equal(t, []interface{}{0, 1, 2}, dst)
+
+	q.Offer(5)
+	var drained []interface{}
+	assert.Equal(t, 3, q.Drain(0, func(e interface{}) { drained = append(drained, e) }))
+	assert.Equal(t, []interface{}{3, 4, 5}, drained)
+
+	q.OfferAll(6, 7)
+	q.Clear()
+	assert.Equal(t, 0, q.Size())
+	assert.Nil(t, q.Poll())
+}
+
+func TestMPSCQueueConcurrent(t *testing.T) {
+	const producers = 8
+	const n = 10000
+
+	q := NewMPSCQueue()
+
+	type item struct{ p, i int }
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < n; i += 2 {
+				if i%4 == 0 {
+					q.Offer(item{p, i})
+					q.Offer(item{p, i + 1})
+				} else {
+					q.OfferAll(item{p, i}, item{p, i + 1})
+				}
+			}
+		}(p)
+	}
+
+	last := make([]int, producers)
+	for p := range last {
+		last[p] = -1
+	}
+	for received := 0; received < producers*n; {
+		e := q.Poll()
+		if e == nil {
+			runtime.Gosched()
+			continue
+		}
+		it := e.(item)
+		if it.i != last[it.p]+1 {
+			t.Fatalf("out of order: %d after %d", it.i, last[it.p])
+		}
+		last[it.p] = it.i
+		received++
+	}
+	wg.Wait()
+	assert.Nil(t, q.Poll())
+	assert.Equal(t, 0, q.Size())
+}
+
+func BenchmarkMPSCQueue(b *testing.B) {
+	q := NewMPSCQueue()
+	benchmarkMPSC(b, q.TryOffer, q.Poll)
+}
+
+func BenchmarkMPSCSynchronizedRingQueue(b *testing.B) {
+	q := NewSynchronizedRingQueue(benchQueueCapacity)
+	benchmarkMPSC(b, q.TryOffer, q.Poll)
+}
+
+func benchmarkMPSC(b *testing.B, offer func(e interface{}) bool, poll func() interface{}) {
+	const producers = 4
+
+	n := b.N / producers
+	var wg sync.WaitGroup
+	b.ResetTimer()
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func() {
+			for i := 0; i < n; i++ {
+				offer(i)
+			}
+			wg.Done()
+		}()
+	}
+	for i := 0; i < n*producers; i++ {
+		for poll() == nil {
+			runtime.Gosched()
+		}
+	}
+	wg.Wait()
+}
@@ -0,0 +1,35 @@
+package concurrent
+
+// OverflowPolicy defines how a bounded collection handles a new element when
+// it has reached its capacity.
+type OverflowPolicy int
+
+const (
+	// OverflowReject rejects the new element.
+	OverflowReject OverflowPolicy = iota
+
+	// OverflowDropOldest removes the oldest element to make room for the new
+	// one.
+	OverflowDropOldest
+
+	// OverflowDropNewest discards the new element.
+	OverflowDropNewest
+
+	// OverflowBlock makes the producer wait until there is room for the new
+	// element.
+	OverflowBlock
+)
+
+// OverflowStats contains the numbers of the elements affected by the overflow
+// policy of a bounded collection.
+type OverflowStats struct {
+	// Rejected is the number of the rejected elements.
+	Rejected uint64
+
+	// DroppedOldest is the number of the oldest elements removed to make room
+	// for the new ones.
+	DroppedOldest uint64
+
+	// DroppedNewest is the number of the discarded new elements.
+	DroppedNewest uint64
+}
@@ -0,0 +1,10 @@
+package concurrent
+
+// cacheLineSize is the assumed size of a CPU cache line.
+const cacheLineSize = 64
+
+// cacheLinePad keeps the fields it separates on different cache lines to
+// avoid false sharing.
+type cacheLinePad struct {
+	_ [cacheLineSize]byte
+}
@@ -0,0 +1,765 @@
+package concurrent
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	segmentExt         = ".seg"
+	offsetFileName     = "consumer.offset"
+	recordHeaderSize   = 8
+	offsetRecordSize   = 36
+	defaultSegmentSize = 64 << 20
+)
+
+// PersistentQueueOptions configures a PersistentQueue.
+type PersistentQueueOptions struct {
+	// Codec converts the elements to bytes and back. Required.
+	Codec Codec
+	// SegmentSize is the size in bytes after which a new segment file is
+	// started. Defaults to 64 MiB.
+	SegmentSize int64
+	// CacheSize is the number of the most recently inserted elements kept in
+	// memory, so the consumer which keeps up with the producers does not read
+	// them from disk.
+	CacheSize int
+	// AckMode makes the consumer offset persist only on Commit. After a
+	// restart, or Rollback, the elements retrieved since the last Commit are
+	// retrieved again.
+	AckMode bool
+	// Sync makes every write to be flushed to the stable storage.
+	Sync bool
+}
+
+// PersistentQueue is a safe for concurrent use FIFO Queue implementation which
+// stores its elements on disk, so the queue survives restarts and may hold
+// more elements than fit in memory.
+//
+// The elements are appended to segment files as records protected by a CRC.
+// The consumer offset is kept in a separate file, and the segments which have
+// been consumed completely are deleted. The offset file has two slots written
+// in turn, so a torn write leaves the previous offset intact.
+//
+// The Queue methods cannot return I/O errors; TryOffer returns false and Poll
+// returns nil on failure, and the error is available through Err. Enqueue and
+// Dequeue report the errors directly.
+type PersistentQueue struct {
+	sync.RWMutex
+	dir  string
+	opts PersistentQueueOptions
+	segs []*segment
+	w    *os.File
+	wseq uint64
+
+	// the read position
+	r    *os.File
+	rseg int
+	rpos int64
+	rseq uint64
+
+	// the committed position
+	cfirst uint64
+	cpos   int64
+	cseq   uint64
+	cgen   uint64
+	offset *os.File
+
+	cache      []cachedRecord
+	cacheFirst uint64
+	err        error
+}
+
+// segment describes a segment file. The segment is named after the sequence
+// number of its first record.
+type segment struct {
+	first uint64
+	count uint64
+	size  int64
+}
+
+type cachedRecord struct {
+	e    interface{}
+	size int64
+}
+
+// OpenPersistentQueue opens the queue stored in the directory dir creating
+// it if necessary. The last segment is truncated to its last valid record
+// which drops the records torn by a crash.
+func OpenPersistentQueue(dir string, opts PersistentQueueOptions) (*PersistentQueue, error) {
+	if opts.Codec == nil {
+		return nil, errors.New("codec is required")
+	}
+	if opts.SegmentSize <= 0 {
+		opts.SegmentSize = defaultSegmentSize
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	q := &PersistentQueue{dir: dir, opts: opts}
+	if err := q.open(); err != nil {
+		q.close()
+		return nil, err
+	}
+	return q, nil
+}
+
+// Size implements Queue.Size
+func (q *PersistentQueue) Size() int {
+	q.RLock()
+	defer q.RUnlock()
+	return int(q.wseq - q.rseq)
+}
+
+// Uncommitted returns the number of the elements retrieved since the last
+// Commit.
+func (q *PersistentQueue) Uncommitted() int {
+	q.RLock()
+	defer q.RUnlock()
+	return int(q.rseq - q.cseq)
+}
+
+// Err returns the last error encountered by the Queue methods.
+func (q *PersistentQueue) Err() error {
+	q.RLock()
+	defer q.RUnlock()
+	return q.err
+}
+
+// Clear implements Queue.Clear. All the segments are deleted.
+func (q *PersistentQueue) Clear() {
+	q.Lock()
+	defer q.Unlock()
+	q.fail(q.clear())
+}
+
+// Offer implements Queue.Offer
+func (q *PersistentQueue) Offer(e interface{}) {
+	q.TryOffer(e)
+}
+
+// TryOffer implements Queue.TryOffer. Returns false if the element cannot be
+// stored.
+func (q *PersistentQueue) TryOffer(e interface{}) bool {
+	return q.Enqueue(e) == nil
+}
+
+// Enqueue stores the element e at the tail of the queue.
+func (q *PersistentQueue) Enqueue(e interface{}) error {
+	b, err := q.opts.Codec.Encode(e)
+	q.Lock()
+	defer q.Unlock()
+	if err != nil {
+		return q.fail(err)
+	}
+	return q.fail(q.append(b))
+}
+
+// Poll implements Queue.Poll. Returns nil if the queue is empty or the
+// element cannot be read.
+func (q *PersistentQueue) Poll() interface{} {
+	e, _ := q.Dequeue()
+	return e
+}
+
+// Dequeue retrieves and removes the head of the queue; returns nil if the
+// queue is empty. Unless the queue is in the ack mode, the consumer offset is
+// persisted.
+func (q *PersistentQueue) Dequeue() (interface{}, error) {
+	q.Lock()
+	defer q.Unlock()
+	if q.rseq == q.wseq {
+		return nil, nil
+	}
+	e, err := q.read(true)
+	if err == nil {
+		err = q.autoCommit()
+	}
+	return e, q.fail(err)
+}
+
+// Commit persists the consumer offset and deletes the segments consumed
+// completely.
+func (q *PersistentQueue) Commit() error {
+	q.Lock()
+	defer q.Unlock()
+	return q.fail(q.commit())
+}
+
+// Rollback moves the consumer back to the last committed offset, so the
+// elements retrieved since then are retrieved again.
+func (q *PersistentQueue) Rollback() error {
+	q.Lock()
+	defer q.Unlock()
+	i := q.segmentIndex(q.cfirst)
+	if i < 0 {
+		return q.fail(ErrCorrupted)
+	}
+	if err := q.openReader(i); err != nil {
+		return q.fail(err)
+	}
+	q.rpos = q.cpos
+	q.rseq = q.cseq
+	return nil
+}
+
+// Peek implements Queue.Peek
+func (q *PersistentQueue) Peek() interface{} {
+	q.Lock()
+	defer q.Unlock()
+	if q.rseq == q.wseq {
+		return nil
+	}
+	e, err := q.read(false)
+	q.fail(err)
+	return e
+}
+
+// Range implements Queue.Range. The elements are read from disk unless they
+// are cached.
+func (q *PersistentQueue) Range(f func(e interface{}) bool) {
+	q.Lock()
+	defer q.Unlock()
+	rseg, rpos, rseq := q.rseg, q.rpos, q.rseq
+	for q.rseq < q.wseq {
+		e, err := q.read(true)
+		if q.fail(err) != nil || !f(e) {
+			break
+		}
+	}
+	if q.rseg != rseg {
+		q.fail(q.openReader(rseg))
+	}
+	q.rpos, q.rseq = rpos, rseq
+}
+
+// ToSlice implements Queue.ToSlice
+func (q *PersistentQueue) ToSlice() []interface{} {
+	return q.ToSliceInto(nil)
+}
+
+// ToSliceInto implements Queue.ToSliceInto
+func (q *PersistentQueue) ToSliceInto(dst []interface{}) []interface{} {
+	if dst == nil {
+		dst = make([]interface{}, 0, q.Size())
+	}
+	dst = dst[:0]
+	q.Range(func(e interface{}) bool {
+		dst = append(dst, e)
+		return true
+	})
+	return dst
+}
+
+// Drain implements Queue.Drain
+func (q *PersistentQueue) Drain(max int, f func(e interface{})) int {
+	q.Lock()
+	n := int(q.wseq - q.rseq)
+	if max > 0 && max < n {
+		n = max
+	}
+	buf := make([]interface{}, n)
+	n = q.drainTo(buf)
+	q.Unlock()
+
+	for _, e := range buf[:n] {
+		f(e)
+	}
+	return n
+}
+
+// DrainTo implements Queue.DrainTo
+func (q *PersistentQueue) DrainTo(dst []interface{}) int {
+	q.Lock()
+	defer q.Unlock()
+	return q.drainTo(dst)
+}
+
+// OfferAll implements Queue.OfferAll. The elements are stored up to the first
+// one which cannot be encoded or written; the error is available through Err.
+func (q *PersistentQueue) OfferAll(es ...interface{}) int {
+	bs := make([][]byte, 0, len(es))
+	var encErr error
+	for _, e := range es {
+		b, err := q.opts.Codec.Encode(e)
+		if err != nil {
+			encErr = err
+			break
+		}
+		bs = append(bs, b)
+	}
+	q.Lock()
+	defer q.Unlock()
+	for i, b := range bs {
+		if q.fail(q.append(b)) != nil {
+			return i
+		}
+	}
+	q.fail(encErr)
+	return len(bs)
+}
+
+// Close closes the queue files. Unless the queue is in the ack mode, the
+// consumer offset is persisted. The queue must not be used after Close.
+func (q *PersistentQueue) Close() error {
+	q.Lock()
+	defer q.Unlock()
+	return q.close()
+}
+
+func (q *PersistentQueue) open() error {
+	if err := q.loadSegments(); err != nil {
+		return err
+	}
+	last := q.segs[len(q.segs)-1]
+	q.wseq = last.first + last.count
+	w, err := os.OpenFile(q.segmentPath(last.first), os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	q.w = w
+	q.cacheFirst = q.wseq
+
+	if q.offset, err = q.openOffset(); err != nil {
+		return err
+	}
+	if err := q.loadOffset(); err != nil {
+		return err
+	}
+	i := q.segmentIndex(q.cfirst)
+	if i < 0 {
+		return fmt.Errorf("%w: offset segment %d not found", ErrCorrupted, q.cfirst)
+	}
+	s := q.segs[i]
+	if q.cseq < s.first || q.cseq > s.first+s.count || q.cpos > s.size {
+		return fmt.Errorf("%w: offset is out of segment %d", ErrCorrupted, s.first)
+	}
+	if err := q.openReader(i); err != nil {
+		return err
+	}
+	q.rpos, q.rseq = q.cpos, q.cseq
+	return q.deleteConsumed()
+}
+
+// loadSegments scans the segment files. The last segment is truncated to
+// its last valid record; a corrupted record in any other segment is an error.
+func (q *PersistentQueue) loadSegments() error {
+	names, err := filepath.Glob(filepath.Join(q.dir, "*"+segmentExt))
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		first, err := strconv.ParseUint(strings.TrimSuffix(filepath.Base(name), segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		q.segs = append(q.segs, &segment{first: first})
+	}
+	sort.Slice(q.segs, func(i, j int) bool { return q.segs[i].first < q.segs[j].first })
+
+	if len(q.segs) == 0 {
+		f, err := q.createSegment(0)
+		if err != nil {
+			return err
+		}
+		return f.Close()
+	}
+
+	for i, s := range q.segs {
+		last := i == len(q.segs)-1
+		if err := q.scanSegment(s, last); err != nil {
+			return err
+		}
+		if !last && s.first+s.count != q.segs[i+1].first {
+			return fmt.Errorf("%w: segment %d does not adjoin segment %d", ErrCorrupted, s.first, q.segs[i+1].first)
+		}
+	}
+	return nil
+}
+
+// scanSegment counts the valid records of the segment s.
+func (q *PersistentQueue) scanSegment(s *segment, last bool) error {
+	path := q.segmentPath(s.first)
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	r := bufio.NewReader(f)
+	var hdr [recordHeaderSize]byte
+	var buf []byte
+	for {
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			break
+		}
+		n := binary.LittleEndian.Uint32(hdr[:4])
+		if int64(n) > fi.Size()-s.size-recordHeaderSize {
+			break
+		}
+		if cap(buf) < int(n) {
+			buf = make([]byte, n)
+		}
+		buf = buf[:n]
+		if _, err := io.ReadFull(r, buf); err != nil {
+			break
+		}
+		if crc32.ChecksumIEEE(buf) != binary.LittleEndian.Uint32(hdr[4:]) {
+			break
+		}
+		s.size += recordHeaderSize + int64(n)
+		s.count++
+	}
+
+	if s.size == fi.Size() {
+		return nil
+	}
+	if !last {
+		return fmt.Errorf("%w: segment %d at %d", ErrCorrupted, s.first, s.size)
+	}
+	return os.Truncate(path, s.size)
+}
+
+// openOffset opens the offset file. A missing or empty file is replaced with
+// a file holding the offset of the start of the first segment, written to
+// a temporary file and renamed, so the offset file always has a valid slot.
+func (q *PersistentQueue) openOffset() (*os.File, error) {
+	path := filepath.Join(q.dir, offsetFileName)
+	fi, err := os.Stat(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err != nil || fi.Size() == 0 {
+		first := q.segs[0].first
+		tmp := path + ".tmp"
+		f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		_, err = f.Write(encodeOffset(0, first, first, 0))
+		if err == nil {
+			err = f.Sync()
+		}
+		if e := f.Close(); err == nil {
+			err = e
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			return nil, err
+		}
+	}
+	return os.OpenFile(path, os.O_RDWR, 0)
+}
+
+// loadOffset loads the valid offset slot of the latest generation.
+func (q *PersistentQueue) loadOffset() error {
+	var b [2 * offsetRecordSize]byte
+	n, err := q.offset.ReadAt(b[:], 0)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	found := false
+	for i := 0; i+offsetRecordSize <= n; i += offsetRecordSize {
+		r := b[i : i+offsetRecordSize]
+		if crc32.ChecksumIEEE(r[:32]) != binary.LittleEndian.Uint32(r[32:]) {
+			continue
+		}
+		gen := binary.LittleEndian.Uint64(r[0:])
+		if found && gen <= q.cgen {
+			continue
+		}
+		found = true
+		q.cgen = gen
+		q.cfirst = binary.LittleEndian.Uint64(r[8:])
+		q.cseq = binary.LittleEndian.Uint64(r[16:])
+		q.cpos = int64(binary.LittleEndian.Uint64(r[24:]))
+	}
+	if !found {
+		return fmt.Errorf("%w: offset file", ErrCorrupted)
+	}
+	return nil
+}
+
+// encodeOffset returns the offset record of the generation gen.
+func encodeOffset(gen, first, seq uint64, pos int64) []byte {
+	b := make([]byte, offsetRecordSize)
+	binary.LittleEndian.PutUint64(b[0:], gen)
+	binary.LittleEndian.PutUint64(b[8:], first)
+	binary.LittleEndian.PutUint64(b[16:], seq)
+	binary.LittleEndian.PutUint64(b[24:], uint64(pos))
+	binary.LittleEndian.PutUint32(b[32:], crc32.ChecksumIEEE(b[:32]))
+	return b
+}
+
+// append writes the record of the element encoded as b starting a new
+// segment if the current one is full. The cache holds the element decoded
+// from b, so the consumer gets its own copy as if it were read from disk.
+func (q *PersistentQueue) append(b []byte) error {
+	var e interface{}
+	if q.opts.CacheSize > 0 {
+		var err error
+		if e, err = q.opts.Codec.Decode(b); err != nil {
+			return err
+		}
+	}
+
+	last := q.segs[len(q.segs)-1]
+	size := recordHeaderSize + int64(len(b))
+	if last.size > 0 && last.size+size > q.opts.SegmentSize {
+		w, err := q.createSegment(q.wseq)
+		if err != nil {
+			return err
+		}
+		q.w.Close()
+		q.w = w
+		last = q.segs[len(q.segs)-1]
+	}
+
+	buf := make([]byte, size)
+	binary.LittleEndian.PutUint32(buf[0:], uint32(len(b)))
+	binary.LittleEndian.PutUint32(buf[4:], crc32.ChecksumIEEE(b))
+	copy(buf[recordHeaderSize:], b)
+	if _, err := q.w.WriteAt(buf, last.size); err != nil {
+		return err
+	}
+	if q.opts.Sync {
+		if err := q.w.Sync(); err != nil {
+			return err
+		}
+	}
+	last.size += size
+	last.count++
+	q.wseq++
+
+	if q.opts.CacheSize > 0 {
+		if len(q.cache) == q.opts.CacheSize {
+			q.cache[0] = cachedRecord{}
+			q.cache = q.cache[1:]
+			q.cacheFirst++
+		}
+		q.cache = append(q.cache, cachedRecord{e: e, size: size})
+	}
+	return nil
+}
+
+// read reads the element at the read position and moves the position past it
+// if advance is true. The queue must not be empty.
+func (q *PersistentQueue) read(advance bool) (interface{}, error) {
+	for s := q.segs[q.rseg]; q.rseq == s.first+s.count; s = q.segs[q.rseg] {
+		if err := q.openReader(q.rseg + 1); err != nil {
+			return nil, err
+		}
+		q.rpos = 0
+	}
+
+	var e interface{}
+	var size int64
+	if i := int64(q.rseq) - int64(q.cacheFirst); i >= 0 && i < int64(len(q.cache)) {
+		e, size = q.cache[i].e, q.cache[i].size
+	} else {
+		var hdr [recordHeaderSize]byte
+		if _, err := q.r.ReadAt(hdr[:], q.rpos); err != nil {
+			return nil, err
+		}
+		b := make([]byte, binary.LittleEndian.Uint32(hdr[:4]))
+		if _, err := q.r.ReadAt(b, q.rpos+recordHeaderSize); err != nil {
+			return nil, err
+		}
+		if crc32.ChecksumIEEE(b) != binary.LittleEndian.Uint32(hdr[4:]) {
+			return nil, fmt.Errorf("%w: segment %d at %d", ErrCorrupted, q.segs[q.rseg].first, q.rpos)
+		}
+		var err error
+		if e, err = q.opts.Codec.Decode(b); err != nil {
+			return nil, err
+		}
+		size = recordHeaderSize + int64(len(b))
+	}
+
+	if advance {
+		q.rpos += size
+		q.rseq++
+	}
+	return e, nil
+}
+
+// consumed drops the cached elements which have been retrieved.
+func (q *PersistentQueue) consumed() {
+	for len(q.cache) > 0 && q.cacheFirst < q.rseq {
+		q.cache[0] = cachedRecord{}
+		q.cache = q.cache[1:]
+		q.cacheFirst++
+	}
+}
+
+func (q *PersistentQueue) drainTo(dst []interface{}) int {
+	n := 0
+	for n < len(dst) && q.rseq < q.wseq {
+		e, err := q.read(true)
+		if q.fail(err) != nil {
+			break
+		}
+		dst[n] = e
+		n++
+	}
+	q.fail(q.autoCommit())
+	return n
+}
+
+// autoCommit commits the read position unless the queue is in the ack mode.
+func (q *PersistentQueue) autoCommit() error {
+	q.consumed()
+	if q.opts.AckMode {
+		return nil
+	}
+	return q.commit()
+}
+
+// commit persists the read position into the offset slot which does not
+// hold the last committed offset.
+func (q *PersistentQueue) commit() error {
+	gen := q.cgen + 1
+	first := q.segs[q.rseg].first
+	if _, err := q.offset.WriteAt(encodeOffset(gen, first, q.rseq, q.rpos), int64(gen%2)*offsetRecordSize); err != nil {
+		return err
+	}
+	if q.opts.Sync {
+		if err := q.offset.Sync(); err != nil {
+			return err
+		}
+	}
+	q.cgen, q.cfirst, q.cseq, q.cpos = gen, first, q.rseq, q.rpos
+	return q.deleteConsumed()
+}
+
+// deleteConsumed deletes the segments preceding the committed one.
+func (q *PersistentQueue) deleteConsumed() error {
+	i := q.segmentIndex(q.cfirst)
+	for _, s := range q.segs[:i] {
+		if err := os.Remove(q.segmentPath(s.first)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	q.segs = append(q.segs[:0], q.segs[i:]...)
+	q.rseg -= i
+	return nil
+}
+
+func (q *PersistentQueue) clear() error {
+	q.closeFiles()
+	for _, s := range q.segs {
+		if err := os.Remove(q.segmentPath(s.first)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	q.segs = nil
+	q.cache = nil
+	q.cacheFirst = q.wseq
+	w, err := q.createSegment(q.wseq)
+	if err != nil {
+		return err
+	}
+	q.w = w
+	if err := q.openReader(0); err != nil {
+		return err
+	}
+	q.rpos, q.rseq = 0, q.wseq
+	return q.commit()
+}
+
+func (q *PersistentQueue) close() error {
+	var err error
+	if q.offset != nil && !q.opts.AckMode && q.r != nil {
+		err = q.commit()
+	}
+	if e := q.closeFiles(); err == nil {
+		err = e
+	}
+	if q.offset != nil {
+		if e := q.offset.Close(); err == nil {
+			err = e
+		}
+		q.offset = nil
+	}
+	return err
+}
+
+func (q *PersistentQueue) closeFiles() error {
+	var err error
+	for _, f := range []*os.File{q.w, q.r} {
+		if f != nil {
+			if e := f.Close(); err == nil {
+				err = e
+			}
+		}
+	}
+	q.w, q.r = nil, nil
+	return err
+}
+
+// createSegment creates an empty segment starting at the sequence number
+// first. Returns the segment file opened for writing.
+func (q *PersistentQueue) createSegment(first uint64) (*os.File, error) {
+	f, err := os.OpenFile(q.segmentPath(first), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	q.segs = append(q.segs, &segment{first: first})
+	return f, nil
+}
+
+// openReader opens the segment i for reading.
+func (q *PersistentQueue) openReader(i int) error {
+	if q.r != nil && i == q.rseg {
+		return nil
+	}
+	r, err := os.Open(q.segmentPath(q.segs[i].first))
+	if err != nil {
+		return err
+	}
+	if q.r != nil {
+		q.r.Close()
+	}
+	q.r = r
+	q.rseg = i
+	return nil
+}
+
+// segmentIndex returns the index of the segment starting at the sequence
+// number first, or -1 if there is none.
+func (q *PersistentQueue) segmentIndex(first uint64) int {
+	for i, s := range q.segs {
+		if s.first == first {
+			return i
+		}
+	}
+	return -1
+}
+
+func (q *PersistentQueue) segmentPath(first uint64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%020d%s", first, segmentExt))
+}
+
+// fail records the error err if it is not nil. Returns err.
+func (q *PersistentQueue) fail(err error) error {
+	if err != nil {
+		q.err = err
+	}
+	return err
+}
@@ -0,0 +1,233 @@
+package concurrent
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func openTestQueue(t *testing.T, dir string, opts PersistentQueueOptions) *PersistentQueue {
+	if opts.Codec == nil {
+		opts.Codec = NewStringCodec()
+	}
+	q, err := OpenPersistentQueue(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q
+}
+
+func segmentFiles(t *testing.T, dir string) []string {
+	names, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return names
+}
+
+func TestPersistentQueueFIFO(t *testing.T) {
+	q := openTestQueue(t, t.TempDir(), PersistentQueueOptions{})
+	defer q.Close()
+	var _ Queue = q
+
+	assert.Nil(t, q.Poll())
+	assert.Nil(t, q.Peek())
+
+	for i := 0; i < 10; i++ {
+		assert.True(t, q.TryOffer(fmt.Sprint(i)))
+	}
+	assert.Equal(t, 10, q.Size())
+	assert.Equal(t, "0", q.Peek())
+	assert.Equal(t, 10, len(q.ToSlice()))
+
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, fmt.Sprint(i), q.Poll())
+	}
+	assert.Nil(t, q.Poll())
+	assert.Equal(t, 0, q.Size())
+
+	assert.False(t, q.TryOffer(1), "Should reject elements the codec does not support")
+	assert.NotNil(t, q.Err())
+	assert.Nil(t, q.Close())
+}
+
+func TestPersistentQueueOfferAllEncodeError(t *testing.T) {
+	q := openTestQueue(t, t.TempDir(), PersistentQueueOptions{})
+	defer q.Close()
+
+	assert.Equal(t, 1, q.OfferAll("a", 1, "b"))
+	assert.NotNil(t, q.Err(), "Encode error should be recorded")
+	assert.Equal(t, []interface{}{"a"}, q.ToSlice())
+}
+
+func TestPersistentQueueSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	opts := PersistentQueueOptions{SegmentSize: 64}
+
+	q := openTestQueue(t, dir, opts)
+	for i := 0; i < 20; i++ {
+		q.Offer(fmt.Sprintf("element-%02d", i))
+	}
+	assert.True(t, len(segmentFiles(t, dir)) > 1, "Should roll segments")
+	for i := 0; i < 5; i++ {
+		q.Poll()
+	}
+	assert.Nil(t, q.Close())
+
+	q = openTestQueue(t, dir, opts)
+	assert.Equal(t, 15, q.Size())
+	assert.Equal(t, "element-05", q.Peek())
+	dst := make([]interface{}, 15)
+	assert.Equal(t, 15, q.DrainTo(dst))
+	assert.Equal(t, "element-19", dst[14])
+	assert.Equal(t, 1, len(segmentFiles(t, dir)), "Consumed segments should be deleted")
+
+	q.Offer("new")
+	assert.Nil(t, q.Close())
+
+	q = openTestQueue(t, dir, opts)
+	defer q.Close()
+	assert.Equal(t, []interface{}{"new"}, q.ToSlice())
+}
+
+func TestPersistentQueueAckMode(t *testing.T) {
+	dir := t.TempDir()
+	opts := PersistentQueueOptions{AckMode: true, SegmentSize: 32}
+
+	q := openTestQueue(t, dir, opts)
+	q.OfferAll("a", "b", "c", "d")
+	assert.Equal(t, "a", q.Poll())
+	assert.Equal(t, "b", q.Poll())
+	assert.Equal(t, 2, q.Uncommitted())
+
+	assert.Nil(t, q.Rollback())
+	assert.Equal(t, 0, q.Uncommitted())
+	assert.Equal(t, "a", q.Poll())
+	assert.Nil(t, q.Commit())
+	assert.Equal(t, "b", q.Poll())
+	assert.Nil(t, q.Close())
+
+	// the uncommitted element is delivered again after restart
+	q = openTestQueue(t, dir, opts)
+	defer q.Close()
+	assert.Equal(t, 3, q.Size())
+	var got []interface{}
+	q.Drain(0, func(e interface{}) { got = append(got, e) })
+	assert.Equal(t, []interface{}{"b", "c", "d"}, got)
+	assert.Nil(t, q.Commit())
+	assert.Equal(t, 1, len(segmentFiles(t, dir)))
+}
+
+func TestPersistentQueueTornWrite(t *testing.T) {
+	dir := t.TempDir()
+	q := openTestQueue(t, dir, PersistentQueueOptions{})
+	q.OfferAll("a", "b")
+	assert.Nil(t, q.Close())
+
+	// simulate a crash in the middle of a record
+	name := segmentFiles(t, dir)[0]
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND, 0)
+	assert.Nil(t, err)
+	f.Write([]byte{10, 0, 0, 0, 1, 2, 3, 4, 'x'})
+	f.Close()
+
+	q = openTestQueue(t, dir, PersistentQueueOptions{})
+	assert.Equal(t, 2, q.Size())
+	q.Offer("c")
+	assert.Equal(t, []interface{}{"a", "b", "c"}, q.ToSlice())
+	assert.Nil(t, q.Close())
+}
+
+func TestPersistentQueueTornOffset(t *testing.T) {
+	dir := t.TempDir()
+	opts := PersistentQueueOptions{AckMode: true}
+	q := openTestQueue(t, dir, opts)
+	q.OfferAll("a", "b", "c")
+	q.Poll()
+	assert.Nil(t, q.Commit())
+	q.Poll()
+	assert.Nil(t, q.Commit())
+	gen := q.cgen
+	assert.Nil(t, q.Close())
+
+	// simulate a crash in the middle of the last offset write
+	name := filepath.Join(dir, offsetFileName)
+	b, err := os.ReadFile(name)
+	assert.Nil(t, err)
+	slot := int(gen%2) * offsetRecordSize
+	for i := slot + offsetRecordSize/2; i < slot+offsetRecordSize; i++ {
+		b[i] = 0
+	}
+	assert.Nil(t, os.WriteFile(name, b, 0o644))
+
+	q = openTestQueue(t, dir, opts)
+	defer q.Close()
+	assert.Equal(t, []interface{}{"b", "c"}, q.ToSlice(), "Previous offset should be used")
+}
+
+func TestPersistentQueueCRC(t *testing.T) {
+	dir := t.TempDir()
+	opts := PersistentQueueOptions{SegmentSize: 16}
+	q := openTestQueue(t, dir, opts)
+	q.OfferAll("aaaaaaaa", "bbbbbbbb", "cccccccc")
+	assert.Nil(t, q.Close())
+
+	// corrupt the payload of the first record
+	name := segmentFiles(t, dir)[0]
+	b, err := os.ReadFile(name)
+	assert.Nil(t, err)
+	b[recordHeaderSize] ^= 0xff
+	assert.Nil(t, os.WriteFile(name, b, 0o644))
+
+	_, err = OpenPersistentQueue(dir, PersistentQueueOptions{Codec: NewStringCodec(), SegmentSize: 16})
+	assert.True(t, errors.Is(err, ErrCorrupted))
+}
+
+func TestPersistentQueueCache(t *testing.T) {
+	dir := t.TempDir()
+	q := openTestQueue(t, dir, PersistentQueueOptions{Codec: NewBytesCodec(), CacheSize: 2})
+	defer q.Close()
+
+	q.OfferAll([]byte("a"), []byte("b"), []byte("c"))
+	assert.Equal(t, 2, len(q.cache))
+
+	// the cached elements are served from memory even if the file is gone
+	assert.Equal(t, []byte("a"), q.Poll())
+	assert.Nil(t, q.r.Close())
+	q.r, _ = os.Open(os.DevNull)
+	assert.Equal(t, []byte("b"), q.Poll())
+	assert.Equal(t, []byte("c"), q.Poll())
+	assert.Equal(t, 0, len(q.cache))
+	assert.Nil(t, q.Err())
+}
+
+func TestPersistentQueueCacheCopy(t *testing.T) {
+	q := openTestQueue(t, t.TempDir(), PersistentQueueOptions{Codec: NewBytesCodec(), CacheSize: 2})
+	defer q.Close()
+
+	e := []byte("a")
+	q.Offer(e)
+	e[0] = 'x'
+	assert.Equal(t, []byte("a"), q.Poll(), "Cached element should not share the producer slice")
+}
+
+func TestPersistentQueueClear(t *testing.T) {
+	dir := t.TempDir()
+	opts := PersistentQueueOptions{SegmentSize: 16}
+	q := openTestQueue(t, dir, opts)
+	q.OfferAll("aaaaaaaa", "bbbbbbbb", "cccccccc")
+	q.Clear()
+	assert.Nil(t, q.Err())
+	assert.Equal(t, 0, q.Size())
+	assert.Equal(t, 1, len(segmentFiles(t, dir)))
+	q.Offer("d")
+	assert.Nil(t, q.Close())
+
+	q = openTestQueue(t, dir, opts)
+	defer q.Close()
+	assert.Equal(t, "d", q.Poll())
+}
@@ -0,0 +1,354 @@
+package concurrent
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PriorityHandle identifies an element added into a PriorityQueue. It is used
+// to update the priority of the element or to remove it from the queue.
+type PriorityHandle struct {
+	e        interface{}
+	priority interface{}
+	seq      uint64
+	index    int
+}
+
+// Value returns the element identified by the handle.
+func (h *PriorityHandle) Value() interface{} {
+	return h.e
+}
+
+// PriorityQueue is a safe for concurrent use unbounded BlockingQueue
+// implementation backed by a binary heap. The head of the queue is the
+// element with the least priority according to the comparator; elements with
+// equal priorities are retrieved in FIFO order.
+//
+// Offer uses the element as its own priority, Add allows to specify the
+// priority separately and returns a handle which can be used to update the
+// priority or to remove the element.
+type PriorityQueue struct {
+	sync.RWMutex
+	heap     []*PriorityHandle
+	cmp      Comparator
+	seq      uint64
+	closed   bool
+	notEmpty cond
+}
+
+// NewPriorityQueue returns pointer to a new PriorityQueue instance which
+// orders the priorities using the comparator cmp.
+func NewPriorityQueue(initialCapacity int, cmp Comparator) *PriorityQueue {
+	return &PriorityQueue{
+		heap: make([]*PriorityHandle, 0, initialCapacity),
+		cmp:  cmp,
+	}
+}
+
+// Size implements Queue.Size
+func (q *PriorityQueue) Size() int {
+	q.RLock()
+	defer q.RUnlock()
+	return len(q.heap)
+}
+
+// Clear implements Queue.Clear
+func (q *PriorityQueue) Clear() {
+	q.Lock()
+	defer q.Unlock()
+	for _, h := range q.heap {
+		h.index = -1
+	}
+	q.heap = make([]*PriorityHandle, 0, cap(q.heap))
+}
+
+// Offer implements Queue.Offer
+func (q *PriorityQueue) Offer(e interface{}) {
+	q.TryOffer(e)
+}
+
+// TryOffer implements Queue.TryOffer
+func (q *PriorityQueue) TryOffer(e interface{}) bool {
+	return q.Add(e, e) != nil
+}
+
+// Add inserts the element e with the priority p into the queue. Returns the
+// handle of the element, or nil if the queue is closed.
+func (q *PriorityQueue) Add(e, p interface{}) *PriorityHandle {
+	q.Lock()
+	defer q.Unlock()
+	return q.add(e, p)
+}
+
+// Update sets the priority of the element identified by the handle h to p.
+// Returns false if the element is not in the queue anymore.
+func (q *PriorityQueue) Update(h *PriorityHandle, p interface{}) bool {
+	q.Lock()
+	defer q.Unlock()
+	if !q.owns(h) {
+		return false
+	}
+	h.priority = p
+	if !q.up(h.index) {
+		q.down(h.index)
+	}
+	return true
+}
+
+// Remove removes the element identified by the handle h from the queue.
+// Returns false if the element is not in the queue anymore.
+func (q *PriorityQueue) Remove(h *PriorityHandle) bool {
+	q.Lock()
+	defer q.Unlock()
+	if !q.owns(h) {
+		return false
+	}
+	q.removeAt(h.index)
+	return true
+}
+
+// Poll implements Queue.Poll
+func (q *PriorityQueue) Poll() interface{} {
+	q.Lock()
+	defer q.Unlock()
+	if len(q.heap) == 0 {
+		return nil
+	}
+	return q.removeAt(0).e
+}
+
+// Peek implements Queue.Peek
+func (q *PriorityQueue) Peek() interface{} {
+	q.RLock()
+	defer q.RUnlock()
+	if len(q.heap) == 0 {
+		return nil
+	}
+	return q.heap[0].e
+}
+
+// Range implements Queue.Range. The elements are visited in no particular
+// order.
+func (q *PriorityQueue) Range(f func(e interface{}) bool) {
+	q.RLock()
+	defer q.RUnlock()
+	for _, h := range q.heap {
+		if !f(h.e) {
+			return
+		}
+	}
+}
+
+// ToSlice implements Queue.ToSlice. The elements are returned in priority
+// order.
+func (q *PriorityQueue) ToSlice() []interface{} {
+	return q.ToSliceInto(nil)
+}
+
+// ToSliceInto implements Queue.ToSliceInto. The elements are copied in
+// priority order.
+func (q *PriorityQueue) ToSliceInto(dst []interface{}) []interface{} {
+	q.RLock()
+	defer q.RUnlock()
+	hs := append(make([]*PriorityHandle, 0, len(q.heap)), q.heap...)
+	sort.Slice(hs, func(i, j int) bool { return q.less(hs[i], hs[j]) })
+	if dst == nil {
+		dst = make([]interface{}, 0, len(hs))
+	}
+	dst = dst[:0]
+	for _, h := range hs {
+		dst = append(dst, h.e)
+	}
+	return dst
+}
+
+// Drain implements Queue.Drain
+func (q *PriorityQueue) Drain(max int, f func(e interface{})) int {
+	q.Lock()
+	n := len(q.heap)
+	if max > 0 && max < n {
+		n = max
+	}
+	buf := make([]interface{}, n)
+	q.drainTo(buf)
+	q.Unlock()
+
+	for _, e := range buf {
+		f(e)
+	}
+	return n
+}
+
+// DrainTo implements Queue.DrainTo
+func (q *PriorityQueue) DrainTo(dst []interface{}) int {
+	q.Lock()
+	defer q.Unlock()
+	return q.drainTo(dst)
+}
+
+// OfferAll implements Queue.OfferAll
+func (q *PriorityQueue) OfferAll(es ...interface{}) int {
+	q.Lock()
+	defer q.Unlock()
+	if q.closed {
+		return 0
+	}
+	for _, e := range es {
+		q.add(e, e)
+	}
+	return len(es)
+}
+
+// Put implements BlockingQueue.Put. The queue is unbounded, so Put never
+// waits.
+func (q *PriorityQueue) Put(ctx context.Context, e interface{}) error {
+	if !q.TryOffer(e) {
+		return ErrClosed
+	}
+	return nil
+}
+
+// Take implements BlockingQueue.Take
+func (q *PriorityQueue) Take(ctx context.Context) (interface{}, error) {
+	q.Lock()
+	defer q.Unlock()
+	for len(q.heap) == 0 {
+		if q.closed {
+			return nil, ErrClosed
+		}
+		if err := q.notEmpty.wait(ctx, q); err != nil {
+			return nil, err
+		}
+	}
+	return q.removeAt(0).e, nil
+}
+
+// OfferTimeout implements BlockingQueue.OfferTimeout
+func (q *PriorityQueue) OfferTimeout(e interface{}, d time.Duration) bool {
+	return q.TryOffer(e)
+}
+
+// PollTimeout implements BlockingQueue.PollTimeout
+func (q *PriorityQueue) PollTimeout(d time.Duration) (interface{}, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	e, err := q.Take(ctx)
+	return e, err == nil
+}
+
+// Close implements BlockingQueue.Close
+func (q *PriorityQueue) Close() {
+	q.Lock()
+	defer q.Unlock()
+	q.closed = true
+	q.notEmpty.broadcast()
+}
+
+// peekHead returns the handle and the element of the head of the queue.
+func (q *PriorityQueue) peekHead() (interface{}, interface{}) {
+	q.RLock()
+	defer q.RUnlock()
+	if len(q.heap) == 0 {
+		return nil, nil
+	}
+	return q.heap[0], q.heap[0].e
+}
+
+// removeHead removes the element identified by the handle h.
+func (q *PriorityQueue) removeHead(h interface{}) bool {
+	return q.Remove(h.(*PriorityHandle))
+}
+
+func (q *PriorityQueue) add(e, p interface{}) *PriorityHandle {
+	if q.closed {
+		return nil
+	}
+	h := &PriorityHandle{e: e, priority: p, seq: q.seq, index: len(q.heap)}
+	q.seq++
+	q.heap = append(q.heap, h)
+	q.up(h.index)
+	q.notEmpty.broadcast()
+	return h
+}
+
+func (q *PriorityQueue) drainTo(dst []interface{}) int {
+	n := len(q.heap)
+	if len(dst) < n {
+		n = len(dst)
+	}
+	for i := 0; i < n; i++ {
+		dst[i] = q.removeAt(0).e
+	}
+	return n
+}
+
+// owns returns true if the handle h identifies an element of the queue.
+func (q *PriorityQueue) owns(h *PriorityHandle) bool {
+	return h != nil && h.index >= 0 && h.index < len(q.heap) && q.heap[h.index] == h
+}
+
+// removeAt removes the element at the position i of the heap.
+func (q *PriorityQueue) removeAt(i int) *PriorityHandle {
+	h := q.heap[i]
+	n := len(q.heap) - 1
+	if i != n {
+		q.swap(i, n)
+	}
+	q.heap[n] = nil
+	q.heap = q.heap[:n]
+	if i != n && !q.up(i) {
+		q.down(i)
+	}
+	h.index = -1
+	return h
+}
+
+func (q *PriorityQueue) less(l, r *PriorityHandle) bool {
+	if c := q.cmp(l.priority, r.priority); c != 0 {
+		return c < 0
+	}
+	return l.seq < r.seq
+}
+
+func (q *PriorityQueue) swap(i, j int) {
+	q.heap[i], q.heap[j] = q.heap[j], q.heap[i]
+	q.heap[i].index = i
+	q.heap[j].index = j
+}
+
+// up moves the element at the position i towards the root. Returns true if
+// the element has been moved.
+func (q *PriorityQueue) up(i int) bool {
+	moved := false
+	for i > 0 {
+		p := (i - 1) / 2
+		if !q.less(q.heap[i], q.heap[p]) {
+			break
+		}
+		q.swap(i, p)
+		i = p
+		moved = true
+	}
+	return moved
+}
+
+// down moves the element at the position i towards the leaves.
+func (q *PriorityQueue) down(i int) {
+	n := len(q.heap)
+	for {
+		c := 2*i + 1
+		if c >= n {
+			return
+		}
+		if r := c + 1; r < n && q.less(q.heap[r], q.heap[c]) {
+			c = r
+		}
+		if !q.less(q.heap[c], q.heap[i]) {
+			return
+		}
+		q.swap(i, c)
+		i = c
+	}
+}
@@ -0,0 +1,203 @@
+package concurrent
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func intComparator(l, r interface{}) int { return l.(int) - r.(int) }
+
+func TestPriorityQueueOrder(t *testing.T) {
+	const n = 1000
+
+	q := NewPriorityQueue(0, intComparator)
+	var _ BlockingQueue = q
+
+	assert.Nil(t, q.Poll())
+	assert.Nil(t, q.Peek())
+
+	for _, v := range rand.Perm(n) {
+		assert.True(t, q.TryOffer(v))
+	}
+	assert.Equal(t, n, q.Size())
+	assert.Equal(t, 0, q.Peek())
+
+	s := q.ToSlice()
+	for i := 0; i < n; i++ {
+		assert.Equal(t, i, s[i])
+	}
+
+	for i := 0; i < n; i++ {
+		assert.Equal(t, i, q.Poll())
+	}
+	assert.Equal(t, 0, q.Size())
+}
+
+func TestPriorityQueueFIFOForEqualPriorities(t *testing.T) {
+	q := NewPriorityQueue(0, intComparator)
+	q.Add("b1", 2)
+	q.Add("a1", 1)
+	q.Add("b2", 2)
+	q.Add("a2", 1)
+	q.Add("b3", 2)
+
+	assert.Equal(t, []interface{}{"a1", "a2", "b1", "b2", "b3"}, q.ToSlice())
+	for _, e := range []string{"a1", "a2", "b1", "b2", "b3"} {
+		assert.Equal(t, e, q.Poll())
+	}
+}
+
+func TestPriorityQueueHandles(t *testing.T) {
+	q := NewPriorityQueue(0, intComparator)
+	hs := make([]*PriorityHandle, 10)
+	for i := range hs {
+		hs[i] = q.Add(i, i*10)
+		assert.Equal(t, i, hs[i].Value())
+	}
+
+	assert.True(t, q.Update(hs[9], -1))
+	assert.Equal(t, 9, q.Peek())
+	assert.True(t, q.Update(hs[9], 100))
+	assert.True(t, q.Update(hs[0], 55))
+	assert.True(t, q.Remove(hs[3]))
+	assert.False(t, q.Remove(hs[3]))
+	assert.False(t, q.Update(hs[3], 0))
+
+	assert.Equal(t, []interface{}{1, 2, 4, 5, 0, 6, 7, 8, 9}, q.ToSlice())
+
+	assert.Equal(t, 1, q.Poll())
+	assert.False(t, q.Remove(hs[1]), "Polled element should not be removable")
+
+	other := NewPriorityQueue(0, intComparator)
+	assert.False(t, other.Remove(hs[2]), "Handle of another queue should be rejected")
+	assert.False(t, q.Remove(nil))
+
+	q.Clear()
+	assert.Equal(t, 0, q.Size())
+	assert.False(t, q.Update(hs[2], 0))
+}
+
+func TestPriorityQueueRandomUpdates(t *testing.T) {
+	const n = 500
+
+	q := NewPriorityQueue(n, intComparator)
+	prio := make(map[*PriorityHandle]int)
+	for i := 0; i < n; i++ {
+		p := rand.Intn(n)
+		prio[q.Add(i, p)] = p
+	}
+	for h := range prio {
+		switch rand.Intn(3) {
+		case 0:
+			p := rand.Intn(n)
+			assert.True(t, q.Update(h, p))
+			prio[h] = p
+		case 1:
+			assert.True(t, q.Remove(h))
+			delete(prio, h)
+		}
+	}
+	assert.Equal(t, len(prio), q.Size())
+
+	last := -1
+	q.Drain(0, func(e interface{}) {
+		for h, p := range prio {
+			if h.Value() == e {
+				assert.True(t, p >= last)
+				last = p
+				return
+			}
+		}
+		t.Fatalf("unexpected element %v", e)
+	})
+}
+
+func TestPriorityQueueBulk(t *testing.T) {
+	q := NewPriorityQueue(0, intComparator)
+	assert.Equal(t, 5, q.OfferAll(5, 3, 1, 4, 2))
+
+	dst := make([]interface{}, 2)
+	assert.Equal(t, 2, q.DrainTo(dst))
+	assert.Equal(t, []interface{}{1, 2}, dst)
+
+	var got []interface{}
+	assert.Equal(t, 3, q.Drain(0, func(e interface{}) { got = append(got, e) }))
+	assert.Equal(t, []interface{}{3, 4, 5}, got)
+
+	q.OfferAll(1, 2, 3)
+	sum := 0
+	q.Range(func(e interface{}) bool {
+		sum += e.(int)
+		return true
+	})
+	assert.Equal(t, 6, sum)
+}
+
+func TestPriorityQueueTake(t *testing.T) {
+	q := NewPriorityQueue(0, intComparator)
+
+	e, ok := q.PollTimeout(10 * time.Millisecond)
+	assert.False(t, ok)
+	assert.Nil(t, e)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.Put(context.Background(), 7)
+	}()
+	e, err := q.Take(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 7, e)
+
+	q.Offer(1)
+	q.Close()
+	assert.False(t, q.TryOffer(2))
+	assert.Nil(t, q.Add(2, 2))
+	assert.Equal(t, ErrClosed, q.Put(context.Background(), 2))
+	assert.Equal(t, 0, q.OfferAll(2, 3))
+
+	e, err = q.Take(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, e)
+	_, err = q.Take(context.Background())
+	assert.Equal(t, ErrClosed, err)
+}
+
+func TestPriorityQueueConcurrent(t *testing.T) {
+	const producers = 4
+	const n = 2000
+
+	q := NewPriorityQueue(0, intComparator)
+	var wg sync.WaitGroup
+	for i := 0; i < producers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 1; j <= n; j++ {
+				q.Offer(j)
+			}
+		}()
+	}
+
+	sum := 0
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			e, err := q.Take(context.Background())
+			if err != nil {
+				return
+			}
+			sum += e.(int)
+		}
+	}()
+
+	wg.Wait()
+	q.Close()
+	<-done
+	assert.Equal(t, producers*n*(n+1)/2, sum)
+}
@@ -0,0 +1,219 @@
+package concurrent
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// ProducerType defines how many goroutines publish into a RingBuffer.
+type ProducerType int
+
+const (
+	// SingleProducer is used when the events are published by one goroutine.
+	SingleProducer ProducerType = iota
+
+	// MultiProducer is used when the events are published by many goroutines.
+	MultiProducer
+)
+
+// EventFactory creates the events which pre-fill a RingBuffer.
+type EventFactory func() interface{}
+
+// RingBuffer is a Disruptor-style ring of pre-allocated mutable events.
+// Producers claim sequences, fill the events and publish them; event
+// processors read the published events through sequence barriers.
+//
+//	seq := rb.Next()
+//	e := rb.Get(seq).(*Event)
+//	... // fill the event
+//	rb.Publish(seq)
+//
+// A producer never overwrites an event until all the gating sequences, which
+// are normally the sequences of the last processors of the pipeline, have
+// passed it.
+type RingBuffer struct {
+	entries  []interface{}
+	mask     int64
+	producer ProducerType
+	wait     WaitStrategy
+	// cursor is the highest published sequence for a single producer, or the
+	// highest claimed sequence for multiple producers
+	cursor *Sequence
+	gating atomic.Pointer[[]*Sequence]
+
+	// single producer state, owned by the producer goroutine
+	next         int64
+	cachedGating int64
+
+	// multiple producer state
+	gatingCache *Sequence
+	available   []atomic.Int64
+}
+
+// NewRingBuffer returns pointer to a new RingBuffer instance. The size must be
+// power of 2. The factory is called size times to pre-fill the ring. The wait
+// strategy is used by the event processors waiting for the events.
+func NewRingBuffer(size int, factory EventFactory, producer ProducerType, wait WaitStrategy) *RingBuffer {
+	rb := &RingBuffer{
+		entries:      newRingBuffer(size),
+		mask:         int64(size - 1),
+		producer:     producer,
+		wait:         wait,
+		cursor:       NewSequence(),
+		next:         InitialSequence,
+		cachedGating: InitialSequence,
+		gatingCache:  NewSequence(),
+	}
+	for i := range rb.entries {
+		rb.entries[i] = factory()
+	}
+	if producer == MultiProducer {
+		rb.available = make([]atomic.Int64, size)
+		for i := range rb.available {
+			rb.available[i].Store(InitialSequence)
+		}
+	}
+	rb.gating.Store(&[]*Sequence{})
+	return rb
+}
+
+// Size returns the number of the events in the ring.
+func (rb *RingBuffer) Size() int {
+	return len(rb.entries)
+}
+
+// Get returns the event for the sequence seq.
+func (rb *RingBuffer) Get(seq int64) interface{} {
+	return rb.entries[seq&rb.mask]
+}
+
+// Cursor returns the highest published sequence for a single producer, or
+// the highest claimed sequence for multiple producers.
+func (rb *RingBuffer) Cursor() int64 {
+	return rb.cursor.Get()
+}
+
+// AddGatingSequences adds the sequences the producers must not overtake.
+func (rb *RingBuffer) AddGatingSequences(seqs ...*Sequence) {
+	for {
+		old := rb.gating.Load()
+		gs := make([]*Sequence, 0, len(*old)+len(seqs))
+		gs = append(append(gs, *old...), seqs...)
+		if rb.gating.CompareAndSwap(old, &gs) {
+			return
+		}
+	}
+}
+
+// NewBarrier returns a new SequenceBarrier for a processor which handles the
+// events after all the dependent sequences. Without dependents the processor
+// handles the events as soon as they are published.
+func (rb *RingBuffer) NewBarrier(dependents ...*Sequence) *SequenceBarrier {
+	return &SequenceBarrier{
+		rb:         rb,
+		dependents: dependents,
+	}
+}
+
+// Next claims the next sequence waiting for the gating sequences if the ring
+// is full.
+func (rb *RingBuffer) Next() int64 {
+	return rb.NextN(1)
+}
+
+// NextN claims the next n sequences waiting for the gating sequences if the
+// ring has no room for them. Returns the highest claimed sequence.
+func (rb *RingBuffer) NextN(n int) int64 {
+	if n < 1 || n > len(rb.entries) {
+		panic("n must be in the range [1, size]")
+	}
+	for {
+		if seq, ok := rb.TryNextN(n); ok {
+			return seq
+		}
+		runtime.Gosched()
+	}
+}
+
+// TryNext claims the next sequence. Returns false if the ring is full.
+func (rb *RingBuffer) TryNext() (int64, bool) {
+	return rb.TryNextN(1)
+}
+
+// TryNextN claims the next n sequences. Returns the highest claimed sequence,
+// or false if the ring has no room for them.
+func (rb *RingBuffer) TryNextN(n int) (int64, bool) {
+	if rb.producer == SingleProducer {
+		next := rb.next + int64(n)
+		if !rb.hasRoom(next, &rb.cachedGating, rb.next) {
+			return InitialSequence, false
+		}
+		rb.next = next
+		return next, true
+	}
+
+	for {
+		current := rb.cursor.Get()
+		next := current + int64(n)
+		cached := rb.gatingCache.Get()
+		if !rb.hasRoom(next, &cached, current) {
+			return InitialSequence, false
+		}
+		rb.gatingCache.Set(cached)
+		if rb.cursor.v.CompareAndSwap(current, next) {
+			return next, true
+		}
+	}
+}
+
+// Publish publishes the event for the sequence seq.
+func (rb *RingBuffer) Publish(seq int64) {
+	rb.PublishRange(seq, seq)
+}
+
+// PublishRange publishes the events for the sequences from lo to hi
+// inclusive.
+func (rb *RingBuffer) PublishRange(lo, hi int64) {
+	if rb.producer == SingleProducer {
+		rb.cursor.Set(hi)
+	} else {
+		for seq := lo; seq <= hi; seq++ {
+			rb.available[seq&rb.mask].Store(seq)
+		}
+	}
+	rb.wait.SignalAll()
+}
+
+// PublishEvent claims the next sequence, calls f to fill the event and
+// publishes it.
+func (rb *RingBuffer) PublishEvent(f func(e interface{}, seq int64)) {
+	seq := rb.Next()
+	f(rb.Get(seq), seq)
+	rb.Publish(seq)
+}
+
+// hasRoom checks if the ring has room for the sequences up to next. The
+// cached minimum of the gating sequences is refreshed only if it is not
+// sufficient.
+func (rb *RingBuffer) hasRoom(next int64, cached *int64, current int64) bool {
+	wrap := next - int64(len(rb.entries))
+	if wrap <= *cached && *cached <= current {
+		return true
+	}
+	*cached = minSequence(*rb.gating.Load(), current)
+	return wrap <= *cached
+}
+
+// highestPublished returns the highest sequence in the range from lo to hi
+// such that all the sequences up to it are published.
+func (rb *RingBuffer) highestPublished(lo, hi int64) int64 {
+	if rb.producer == SingleProducer {
+		return hi
+	}
+	for seq := lo; seq <= hi; seq++ {
+		if rb.available[seq&rb.mask].Load() != seq {
+			return seq - 1
+		}
+	}
+	return hi
+}
@@ -0,0 +1,163 @@
+package concurrent
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testEvent struct {
+	value   int64
+	doubled int64
+}
+
+func newTestEvent() interface{} {
+	return &testEvent{}
+}
+
+func TestRingBufferPreallocated(t *testing.T) {
+	rb := NewRingBuffer(4, newTestEvent, SingleProducer, NewIdleWaitStrategy(NewYeildingIdleStrategy()))
+	assert.Equal(t, 4, rb.Size())
+	assert.Equal(t, InitialSequence, rb.Cursor())
+	for i := int64(0); i < 4; i++ {
+		assert.Same(t, rb.Get(i), rb.Get(i+4))
+		assert.NotSame(t, rb.Get(i), rb.Get(i+1))
+	}
+	assert.Panics(t, func() { NewRingBuffer(3, newTestEvent, SingleProducer, nil) })
+}
+
+func TestRingBufferGating(t *testing.T) {
+	for _, producer := range []ProducerType{SingleProducer, MultiProducer} {
+		rb := NewRingBuffer(4, newTestEvent, producer, NewIdleWaitStrategy(NewYeildingIdleStrategy()))
+		consumer := NewSequence()
+		rb.AddGatingSequences(consumer)
+
+		hi, ok := rb.TryNextN(4)
+		assert.True(t, ok)
+		assert.Equal(t, int64(3), hi)
+		rb.PublishRange(0, 3)
+		assert.Equal(t, int64(3), rb.Cursor())
+
+		_, ok = rb.TryNext()
+		assert.False(t, ok, "Should not overtake the gating sequence")
+
+		consumer.Set(1)
+		seq, ok := rb.TryNext()
+		assert.True(t, ok)
+		assert.Equal(t, int64(4), seq)
+		seq, ok = rb.TryNext()
+		assert.True(t, ok)
+		assert.Equal(t, int64(5), seq)
+		_, ok = rb.TryNext()
+		assert.False(t, ok)
+	}
+}
+
+func TestSequenceBarrierMultiProducer(t *testing.T) {
+	rb := NewRingBuffer(8, newTestEvent, MultiProducer, NewIdleWaitStrategy(NewYeildingIdleStrategy()))
+	b := rb.NewBarrier()
+
+	s0 := rb.Next()
+	s1 := rb.Next()
+	s2 := rb.Next()
+	rb.Publish(s0)
+	rb.Publish(s2)
+
+	avail, err := b.WaitFor(0)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), avail, "Should stop at the first unpublished sequence")
+
+	rb.Publish(s1)
+	avail, err = b.WaitFor(1)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), avail)
+
+	b.Alert()
+	_, err = b.WaitFor(3)
+	assert.Equal(t, ErrAlerted, err)
+	b.ClearAlert()
+	assert.False(t, b.IsAlerted())
+}
+
+func TestBlockingWaitStrategy(t *testing.T) {
+	rb := NewRingBuffer(8, newTestEvent, SingleProducer, NewBlockingWaitStrategy(NewYeildingIdleStrategy()))
+	b := rb.NewBarrier()
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		rb.PublishEvent(func(e interface{}, seq int64) { e.(*testEvent).value = 42 })
+	}()
+	avail, err := b.WaitFor(0)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), avail)
+	assert.Equal(t, int64(42), rb.Get(0).(*testEvent).value)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		b.Alert()
+	}()
+	_, err = b.WaitFor(1)
+	assert.Equal(t, ErrAlerted, err)
+}
+
+func TestWaitStrategyNilIdle(t *testing.T) {
+	for _, ws := range []WaitStrategy{NewIdleWaitStrategy(nil), NewBlockingWaitStrategy(nil)} {
+		rb := NewRingBuffer(8, newTestEvent, SingleProducer, ws)
+		// the dependent barrier makes both strategies call the idle strategy
+		up := rb.NewBarrier()
+		dep := NewSequence()
+		b := rb.NewBarrier(dep)
+
+		rb.PublishEvent(func(interface{}, int64) {})
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			dep.Set(0)
+		}()
+		avail, err := b.WaitFor(0)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), avail)
+		avail, err = up.WaitFor(0)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), avail)
+	}
+}
+
+func TestRingBufferMultiProducerConcurrent(t *testing.T) {
+	const producers = 4
+	const n = 5000
+
+	rb := NewRingBuffer(64, newTestEvent, MultiProducer, NewIdleWaitStrategy(NewYeildingIdleStrategy()))
+
+	sums := make([]int64, 2)
+	p := NewEventProcessor(rb, rb.NewBarrier(), EventHandlerFunc(func(e interface{}, seq int64, _ bool) error {
+		sums[0] += e.(*testEvent).value
+		return nil
+	}))
+	rb.AddGatingSequences(p.Sequence())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- p.Run(ctx) }()
+
+	var wg sync.WaitGroup
+	for i := 0; i < producers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for v := int64(1); v <= n; v++ {
+				rb.PublishEvent(func(e interface{}, seq int64) { e.(*testEvent).value = v })
+			}
+		}()
+	}
+	wg.Wait()
+
+	for p.Sequence().Get() < producers*n-1 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+	assert.Equal(t, int64(producers*n*(n+1)/2), sums[0])
+}
@@ -0,0 +1,51 @@
+package concurrent
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// InitialSequence is the value of a new Sequence. The first published
+// sequence is InitialSequence + 1.
+const InitialSequence int64 = -1
+
+// Sequence is a padded atomic counter which tracks the progress of a
+// RingBuffer producer or an event processor.
+type Sequence struct {
+	_ cacheLinePad
+	v atomic.Int64
+	_ cacheLinePad
+}
+
+// NewSequence returns pointer to a new Sequence instance set to
+// InitialSequence.
+func NewSequence() *Sequence {
+	s := &Sequence{}
+	s.v.Store(InitialSequence)
+	return s
+}
+
+// Get returns the current value of the sequence.
+func (s *Sequence) Get() int64 {
+	return s.v.Load()
+}
+
+// Set sets the value of the sequence.
+func (s *Sequence) Set(v int64) {
+	s.v.Store(v)
+}
+
+// minSequence returns the minimum value of the sequences, or def if there are
+// no sequences.
+func minSequence(seqs []*Sequence, def int64) int64 {
+	if len(seqs) == 0 {
+		return def
+	}
+	m := int64(math.MaxInt64)
+	for _, s := range seqs {
+		if v := s.Get(); v < m {
+			m = v
+		}
+	}
+	return m
+}
@@ -0,0 +1,44 @@
+package concurrent
+
+import (
+	"sync/atomic"
+)
+
+// SequenceBarrier coordinates an event processor with the RingBuffer cursor
+// and the processors it depends on.
+type SequenceBarrier struct {
+	rb         *RingBuffer
+	dependents []*Sequence
+	alerted    atomic.Bool
+}
+
+// WaitFor waits until the sequence seq is available for processing. Returns
+// the highest sequence which is available, or ErrAlerted if the barrier has
+// been alerted.
+func (b *SequenceBarrier) WaitFor(seq int64) (int64, error) {
+	if b.IsAlerted() {
+		return InitialSequence, ErrAlerted
+	}
+	avail, err := b.rb.wait.WaitFor(seq, b.rb.cursor, b.dependents, b)
+	if err != nil {
+		return avail, err
+	}
+	return b.rb.highestPublished(seq, avail), nil
+}
+
+// Alert makes the current and the subsequent WaitFor calls return ErrAlerted
+// until the alert is cleared.
+func (b *SequenceBarrier) Alert() {
+	b.alerted.Store(true)
+	b.rb.wait.SignalAll()
+}
+
+// ClearAlert clears the alert.
+func (b *SequenceBarrier) ClearAlert() {
+	b.alerted.Store(false)
+}
+
+// IsAlerted returns true if the barrier has been alerted.
+func (b *SequenceBarrier) IsAlerted() bool {
+	return b.alerted.Load()
+}
@@ -0,0 +1,187 @@
+package concurrent
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// SPSCQueue is a lock-free bounded queue which is safe for concurrent use by
+// one producer and one consumer goroutine. Offer, TryOffer, OfferN and Put
+// must be called by the producer only, Poll, PollN, Peek and Take must be
+// called by the consumer only.
+//
+// The elements must not be nil.
+type SPSCQueue struct {
+	_ cacheLinePad
+	// tail is the sequence of the next element to be written by the producer
+	tail atomic.Int64
+	// headCache is the last head observed by the producer
+	headCache int64
+	_         cacheLinePad
+	// head is the sequence of the next element to be read by the consumer
+	head atomic.Int64
+	// tailCache is the last tail observed by the consumer
+	tailCache int64
+	_         cacheLinePad
+	buf       []interface{}
+	mask      int64
+	idle      IdleStrategy
+}
+
+// NewSPSCQueue returns pointer to a new SPSCQueue instance. The capacity must
+// be power of 2. The idle strategy is used by Put and Take while they wait;
+// if it is nil, the yielding strategy is used.
+func NewSPSCQueue(capacity int, idle IdleStrategy) *SPSCQueue {
+	if idle == nil {
+		idle = NewYeildingIdleStrategy()
+	}
+	return &SPSCQueue{
+		buf:  newRingBuffer(capacity),
+		mask: int64(capacity - 1),
+		idle: idle,
+	}
+}
+
+// Size returns the number of the elements in the queue.
+func (q *SPSCQueue) Size() int {
+	h := q.head.Load()
+	n := q.tail.Load() - h
+	if n > int64(len(q.buf)) {
+		n = int64(len(q.buf))
+	}
+	return int(n)
+}
+
+// Capacity returns the maximum number of the elements in the queue.
+func (q *SPSCQueue) Capacity() int {
+	return len(q.buf)
+}
+
+// Offer implements Queue.Offer
+func (q *SPSCQueue) Offer(e interface{}) {
+	q.TryOffer(e)
+}
+
+// TryOffer inserts the element e into the queue. Returns false if the queue is
+// full.
+func (q *SPSCQueue) TryOffer(e interface{}) bool {
+	t := q.tail.Load()
+	if q.free(t, 1) < 1 {
+		return false
+	}
+	q.buf[t&q.mask] = e
+	q.tail.Store(t + 1)
+	return true
+}
+
+// OfferN inserts as many elements of es as there is room for.
+// Returns the number of the elements inserted.
+func (q *SPSCQueue) OfferN(es []interface{}) int {
+	t := q.tail.Load()
+	n := q.free(t, int64(len(es)))
+	if n > int64(len(es)) {
+		n = int64(len(es))
+	}
+	if n == 0 {
+		return 0
+	}
+	i := t & q.mask
+	c := copy(q.buf[i:], es[:n])
+	copy(q.buf, es[c:n])
+	q.tail.Store(t + n)
+	return int(n)
+}
+
+// Poll retrieves and removes the head of the queue; returns nil if the queue
+// is empty.
+func (q *SPSCQueue) Poll() interface{} {
+	h := q.head.Load()
+	if q.available(h, 1) < 1 {
+		return nil
+	}
+	i := h & q.mask
+	e := q.buf[i]
+	q.buf[i] = nil
+	q.head.Store(h + 1)
+	return e
+}
+
+// PollN removes up to len(dst) elements from the head of the queue and
+// stores them into dst. Returns the number of the elements removed.
+func (q *SPSCQueue) PollN(dst []interface{}) int {
+	h := q.head.Load()
+	n := q.available(h, int64(len(dst)))
+	if n > int64(len(dst)) {
+		n = int64(len(dst))
+	}
+	if n == 0 {
+		return 0
+	}
+	i := h & q.mask
+	c := copy(dst[:n], q.buf[i:])
+	copy(dst[c:n], q.buf)
+	for j := int64(0); j < n; j++ {
+		q.buf[(h+j)&q.mask] = nil
+	}
+	q.head.Store(h + n)
+	return int(n)
+}
+
+// Peek retrieves, but does not remove, the head of the queue; returns nil if
+// the queue is empty.
+func (q *SPSCQueue) Peek() interface{} {
+	h := q.head.Load()
+	if q.available(h, 1) < 1 {
+		return nil
+	}
+	return q.buf[h&q.mask]
+}
+
+// Put inserts the element e into the queue idling while the queue is full.
+// Returns the context error if ctx is done before the element is inserted.
+func (q *SPSCQueue) Put(ctx context.Context, e interface{}) error {
+	for !q.TryOffer(e) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		q.idle.Idle()
+	}
+	return nil
+}
+
+// Take retrieves and removes the head of the queue idling while the queue is
+// empty. Returns the context error if ctx is done before an element becomes
+// available.
+func (q *SPSCQueue) Take(ctx context.Context) (interface{}, error) {
+	for {
+		if e := q.Poll(); e != nil {
+			return e, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		q.idle.Idle()
+	}
+}
+
+// free returns the number of the free slots for the producer at the tail t.
+// The head is re-read only if the cached one does not give enough room.
+func (q *SPSCQueue) free(t, want int64) int64 {
+	c := int64(len(q.buf))
+	if n := c - (t - q.headCache); n >= want {
+		return n
+	}
+	q.headCache = q.head.Load()
+	return c - (t - q.headCache)
+}
+
+// available returns the number of the elements available for the consumer at
+// the head h. The tail is re-read only if the cached one does not give enough
+// elements.
+func (q *SPSCQueue) available(h, want int64) int64 {
+	if n := q.tailCache - h; n >= want {
+		return n
+	}
+	q.tailCache = q.tail.Load()
+	return q.tailCache - h
+}
@@ -0,0 +1,158 @@
+package concurrent
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSPSCQueueOfferPoll(t *testing.T) {
+	const c = 4
+
+	q := NewSPSCQueue(c, nil)
+	assert.Equal(t, c, q.Capacity())
+	assert.Nil(t, q.Poll())
+	assert.Nil(t, q.Peek())
+
+	for r := 0; r < 3; r++ {
+		for i := 0; i < c; i++ {
+			assert.True(t, q.TryOffer(i))
+		}
+		assert.False(t, q.TryOffer(c))
+		assert.Equal(t, c, q.Size())
+
+		for i := 0; i < c; i++ {
+			assert.Equal(t, i, q.Peek())
+			assert.Equal(t, i, q.Poll())
+		}
+		assert.Nil(t, q.Poll())
+		assert.Equal(t, 0, q.Size())
+	}
+
+	assert.Panics(t, func() { NewSPSCQueue(3, nil) })
+}
+
+func TestSPSCQueueOfferNPollN(t *testing.T) {
+	const c = 8
+
+	for p := 0; p < c; p++ {
+		q := NewSPSCQueue(c, nil)
+		for i := 0; i < p; i++ {
+			q.Offer(-1)
+			q.Poll()
+		}
+
+		assert.Equal(t, 0, q.PollN(make([]interface{}, 1)))
+		assert.Equal(t, 6, q.OfferN([]interface{}{0, 1, 2, 3, 4, 5}))
+		assert.Equal(t, 2, q.OfferN([]interface{}{6, 7, 8}))
+		assert.Equal(t, 0, q.OfferN([]interface{}{8}))
+
+		dst := make([]interface{}, 5)
+		assert.Equal(t, 5, q.PollN(dst))
+		assert.Equal(t, []interface{}{0, 1, 2, 3, 4}, dst)
+		assert.Equal(t, 3, q.PollN(dst))
+		assert.Equal(t, []interface{}{5, 6, 7}, dst[:3])
+		assert.Equal(t, 0, q.Size())
+	}
+}
+
+func TestSPSCQueueConcurrent(t *testing.T) {
+	const n = 100000
+
+	q := NewSPSCQueue(64, nil)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		es := make([]interface{}, 0, 7)
+		for i := 0; i < n; {
+			if i%3 == 0 {
+				q.Put(context.Background(), i)
+				i++
+				continue
+			}
+			es = es[:0]
+			for j := i; j < n && len(es) < cap(es); j++ {
+				es = append(es, j)
+			}
+			k := q.OfferN(es)
+			if k == 0 {
+				runtime.Gosched()
+			}
+			i += k
+		}
+	}()
+
+	dst := make([]interface{}, 5)
+	for expected := 0; expected < n; {
+		if expected%2 == 0 {
+			e, err := q.Take(context.Background())
+			if err != nil || e != expected {
+				t.Fatalf("expected %d, got %v, %v", expected, e, err)
+			}
+			expected++
+			continue
+		}
+		k := q.PollN(dst)
+		if k == 0 {
+			runtime.Gosched()
+		}
+		for i := 0; i < k; i++ {
+			if dst[i] != expected {
+				t.Fatalf("expected %d, got %v", expected, dst[i])
+			}
+			expected++
+		}
+	}
+	<-done
+	assert.Equal(t, 0, q.Size())
+}
+
+func TestSPSCQueueTimeout(t *testing.T) {
+	q := NewSPSCQueue(2, NewSleepingIdleStrategy(time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	e, err := q.Take(ctx)
+	assert.Nil(t, e)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	q.Offer(0)
+	q.Offer(1)
+	assert.Equal(t, context.DeadlineExceeded, q.Put(ctx, 2))
+}
+
+func BenchmarkSPSCQueue(b *testing.B) {
+	q := NewSPSCQueue(1024, nil)
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < b.N; i++ {
+			q.Take(context.Background())
+		}
+		close(done)
+	}()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q.Put(context.Background(), i)
+	}
+	<-done
+}
+
+func BenchmarkSPSCSynchronizedRingQueue(b *testing.B) {
+	q := NewBoundedSynchronizedRingQueue(1024, OverflowBlock)
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < b.N; i++ {
+			q.Take(context.Background())
+		}
+		close(done)
+	}()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q.Offer(i)
+	}
+	<-done
+}
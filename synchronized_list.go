@@ -1,6 +1,8 @@
 package concurrent
 
 import (
+	"context"
+	"sort"
 	"sync"
 )
 
@@ -9,6 +11,10 @@ type SynchronizedList struct {
 	sync.RWMutex
 	data     []interface{}
 	capacity int
+	bounded  bool
+	policy   OverflowPolicy
+	notFull  cond
+	version  uint64
 }
 
 // NewSynchronizedList returns pointer to a new SynchronizedList instance
@@ -19,6 +25,24 @@ func NewSynchronizedList(capacity int) *SynchronizedList {
 	}
 }
 
+// NewBoundedSynchronizedList returns pointer to a new SynchronizedList
+// instance which may not contain more than capacity elements. The policy
+// defines how the list handles a new element when it is full:
+// OverflowReject makes the adding methods return ErrFull, OverflowDropOldest
+// removes the first element of the list, OverflowDropNewest discards the new
+// element and makes the adding methods return ErrFull, OverflowBlock makes the
+// adding methods wait for room. An element inserted at the head of a full
+// OverflowDropOldest list is discarded the same way.
+func NewBoundedSynchronizedList(capacity int, policy OverflowPolicy) *SynchronizedList {
+	if capacity < 1 {
+		panic("capacity must be positive")
+	}
+	l := NewSynchronizedList(capacity)
+	l.bounded = true
+	l.policy = policy
+	return l
+}
+
 // Size implements List.Size
 func (l *SynchronizedList) Size() int {
 	l.RLock()
@@ -31,14 +55,43 @@ func (l *SynchronizedList) Size() int {
 func (l *SynchronizedList) Clear() {
 	l.Lock()
 	l.data = make([]interface{}, 0, l.capacity)
+	l.version++
+	l.notFull.broadcast()
 	l.Unlock()
 }
 
-// Add implements List.Clear
+// Add implements List.Add
 func (l *SynchronizedList) Add(v interface{}) {
+	l.TryAdd(v)
+}
+
+// TryAdd implements List.TryAdd
+func (l *SynchronizedList) TryAdd(v interface{}) error {
 	l.Lock()
-	l.data = append(l.data, v)
-	l.Unlock()
+	defer l.Unlock()
+	l.block()
+	return l.add(v)
+}
+
+// AddWait adds the element e into the list waiting until the list has room for
+// it or ctx is done. Returns the context error if ctx is done before the
+// element is added.
+func (l *SynchronizedList) AddWait(ctx context.Context, e interface{}) error {
+	l.Lock()
+	defer l.Unlock()
+	if err := l.awaitRoom(ctx); err != nil {
+		return err
+	}
+	return l.add(e)
+}
+
+// Capacity returns the maximum number of elements the list may contain, or 0
+// if the list is not bounded.
+func (l *SynchronizedList) Capacity() int {
+	if !l.bounded {
+		return 0
+	}
+	return l.capacity
 }
 
 // Get implements List.Clear
@@ -63,6 +116,8 @@ func (l *SynchronizedList) Remove(e interface{}, eq Equals) bool {
 			default:
 				l.data = append(l.data[:i], l.data[i+1:]...)
 			}
+			l.version++
+			l.notFull.broadcast()
 			return true
 		}
 	}
@@ -79,3 +134,311 @@ func (l *SynchronizedList) Range(f func(e interface{}) bool) {
 		}
 	}
 }
+
+// AddAll implements List.AddAll
+func (l *SynchronizedList) AddAll(es ...interface{}) int {
+	l.Lock()
+	defer l.Unlock()
+	if !l.bounded {
+		l.data = append(l.data, es...)
+		l.version++
+		return len(es)
+	}
+	for i, e := range es {
+		l.block()
+		if err := l.add(e); err != nil {
+			return i
+		}
+	}
+	return len(es)
+}
+
+// RemoveAll implements List.RemoveAll
+func (l *SynchronizedList) RemoveAll(eq Equals, es ...interface{}) int {
+	return l.RemoveIf(func(o interface{}) bool {
+		for _, e := range es {
+			if eq(e, o) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// RetainAll implements List.RetainAll
+func (l *SynchronizedList) RetainAll(f func(e interface{}) bool) int {
+	return l.RemoveIf(func(e interface{}) bool { return !f(e) })
+}
+
+// RemoveIf implements List.RemoveIf
+func (l *SynchronizedList) RemoveIf(f func(e interface{}) bool) int {
+	l.Lock()
+	defer l.Unlock()
+	j := 0
+	for _, e := range l.data {
+		if !f(e) {
+			l.data[j] = e
+			j++
+		}
+	}
+	n := len(l.data) - j
+	for i := j; i < len(l.data); i++ {
+		l.data[i] = nil
+	}
+	l.data = l.data[:j]
+	if n > 0 {
+		l.version++
+		l.notFull.broadcast()
+	}
+	return n
+}
+
+// ToSlice implements List.ToSlice
+func (l *SynchronizedList) ToSlice() []interface{} {
+	return l.ToSliceInto(nil)
+}
+
+// ToSliceInto implements List.ToSliceInto
+func (l *SynchronizedList) ToSliceInto(dst []interface{}) []interface{} {
+	l.RLock()
+	defer l.RUnlock()
+	if dst == nil {
+		dst = make([]interface{}, 0, len(l.data))
+	}
+	return append(dst[:0], l.data...)
+}
+
+// Insert implements List.Insert
+func (l *SynchronizedList) Insert(i int, e interface{}) {
+	l.TryInsert(i, e)
+}
+
+// TryInsert implements List.TryInsert
+func (l *SynchronizedList) TryInsert(i int, e interface{}) error {
+	l.Lock()
+	defer l.Unlock()
+	l.block()
+	if i < 0 || i > len(l.data) {
+		panic("index out of range")
+	}
+	_, err := l.insert(i, e)
+	return err
+}
+
+// Set implements List.Set
+func (l *SynchronizedList) Set(i int, e interface{}) interface{} {
+	l.Lock()
+	defer l.Unlock()
+	o := l.data[i]
+	l.data[i] = e
+	l.version++
+	return o
+}
+
+// RemoveAt implements List.RemoveAt
+func (l *SynchronizedList) RemoveAt(i int) interface{} {
+	l.Lock()
+	defer l.Unlock()
+	return l.removeAt(i)
+}
+
+// IndexOf implements List.IndexOf
+func (l *SynchronizedList) IndexOf(e interface{}, eq Equals) int {
+	l.RLock()
+	defer l.RUnlock()
+	for i, o := range l.data {
+		if eq(e, o) {
+			return i
+		}
+	}
+	return -1
+}
+
+// LastIndexOf implements List.LastIndexOf
+func (l *SynchronizedList) LastIndexOf(e interface{}, eq Equals) int {
+	l.RLock()
+	defer l.RUnlock()
+	for i := len(l.data) - 1; i >= 0; i-- {
+		if eq(e, l.data[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Contains implements List.Contains
+func (l *SynchronizedList) Contains(e interface{}, eq Equals) bool {
+	return l.IndexOf(e, eq) >= 0
+}
+
+// SubList implements List.SubList
+func (l *SynchronizedList) SubList(from, to int) List {
+	l.RLock()
+	defer l.RUnlock()
+	s := l.data[from:to]
+	r := NewSynchronizedList(len(s))
+	r.data = append(r.data, s...)
+	return r
+}
+
+// TryGet implements List.TryGet
+func (l *SynchronizedList) TryGet(i int) (interface{}, bool) {
+	l.RLock()
+	defer l.RUnlock()
+	if i < 0 || i >= len(l.data) {
+		return nil, false
+	}
+	return l.data[i], true
+}
+
+// GetOrDefault implements List.GetOrDefault
+func (l *SynchronizedList) GetOrDefault(i int, def interface{}) interface{} {
+	if v, ok := l.TryGet(i); ok {
+		return v
+	}
+	return def
+}
+
+// First implements List.First
+func (l *SynchronizedList) First() (interface{}, bool) {
+	return l.TryGet(0)
+}
+
+// Last implements List.Last
+func (l *SynchronizedList) Last() (interface{}, bool) {
+	l.RLock()
+	defer l.RUnlock()
+	if len(l.data) == 0 {
+		return nil, false
+	}
+	return l.data[len(l.data)-1], true
+}
+
+// PopFirst implements List.PopFirst
+func (l *SynchronizedList) PopFirst() (interface{}, bool) {
+	l.Lock()
+	defer l.Unlock()
+	if len(l.data) == 0 {
+		return nil, false
+	}
+	return l.removeAt(0), true
+}
+
+// PopLast implements List.PopLast
+func (l *SynchronizedList) PopLast() (interface{}, bool) {
+	l.Lock()
+	defer l.Unlock()
+	if len(l.data) == 0 {
+		return nil, false
+	}
+	return l.removeAt(len(l.data) - 1), true
+}
+
+// Sort implements List.Sort
+func (l *SynchronizedList) Sort(less Less) {
+	l.Lock()
+	sort.Slice(l.data, func(i, j int) bool { return less(l.data[i], l.data[j]) })
+	l.version++
+	l.Unlock()
+}
+
+// SortStable implements List.SortStable
+func (l *SynchronizedList) SortStable(less Less) {
+	l.Lock()
+	sort.SliceStable(l.data, func(i, j int) bool { return less(l.data[i], l.data[j]) })
+	l.version++
+	l.Unlock()
+}
+
+// BinarySearch implements List.BinarySearch
+func (l *SynchronizedList) BinarySearch(e interface{}, cmp Comparator) (int, bool) {
+	l.RLock()
+	defer l.RUnlock()
+	i := sort.Search(len(l.data), func(i int) bool { return cmp(l.data[i], e) >= 0 })
+	return i, i < len(l.data) && cmp(l.data[i], e) == 0
+}
+
+// InsertSorted implements List.InsertSorted
+func (l *SynchronizedList) InsertSorted(e interface{}, cmp Comparator) int {
+	i, _ := l.TryInsertSorted(e, cmp)
+	return i
+}
+
+// TryInsertSorted implements List.TryInsertSorted
+func (l *SynchronizedList) TryInsertSorted(e interface{}, cmp Comparator) (int, error) {
+	l.Lock()
+	defer l.Unlock()
+	l.block()
+	i := sort.Search(len(l.data), func(i int) bool { return cmp(l.data[i], e) > 0 })
+	return l.insert(i, e)
+}
+
+// Version implements List.Version
+func (l *SynchronizedList) Version() uint64 {
+	l.RLock()
+	defer l.RUnlock()
+	return l.version
+}
+
+// Iterator implements List.Iterator
+func (l *SynchronizedList) Iterator(failFast bool) Iterator {
+	l.RLock()
+	defer l.RUnlock()
+	data := append(make([]interface{}, 0, len(l.data)), l.data...)
+	return newSnapshotIterator(l, data, l.version, failFast)
+}
+
+func (l *SynchronizedList) full() bool {
+	return l.bounded && len(l.data) >= l.capacity
+}
+
+// awaitRoom waits until the list has room for a new element unless the list
+// makes room by dropping the oldest elements.
+func (l *SynchronizedList) awaitRoom(ctx context.Context) error {
+	for l.full() && l.policy != OverflowDropOldest {
+		if err := l.notFull.wait(ctx, l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// block waits for room if the list blocks on overflow.
+func (l *SynchronizedList) block() {
+	if l.policy == OverflowBlock {
+		l.awaitRoom(context.Background())
+	}
+}
+
+func (l *SynchronizedList) add(e interface{}) error {
+	_, err := l.insert(len(l.data), e)
+	return err
+}
+
+// insert inserts the element e at the position i applying the overflow
+// policy. Returns the resulting position of the element, or -1 and ErrFull if
+// the element has been rejected or dropped.
+func (l *SynchronizedList) insert(i int, e interface{}) (int, error) {
+	if l.full() {
+		if l.policy != OverflowDropOldest || i == 0 {
+			return -1, ErrFull
+		}
+		l.removeAt(0)
+		i--
+	}
+	l.data = append(l.data, nil)
+	copy(l.data[i+1:], l.data[i:])
+	l.data[i] = e
+	l.version++
+	return i, nil
+}
+
+func (l *SynchronizedList) removeAt(i int) interface{} {
+	e := l.data[i]
+	copy(l.data[i:], l.data[i+1:])
+	l.data[len(l.data)-1] = nil
+	l.data = l.data[:len(l.data)-1]
+	l.version++
+	l.notFull.broadcast()
+	return e
+}
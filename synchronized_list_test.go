@@ -1,7 +1,9 @@
 package concurrent
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"sort"
 	"sync"
@@ -105,3 +107,462 @@ func TestSynchronizedListRange(t *testing.T) {
 		assert.Equal(t, i*i, elms[i])
 	}
 }
+
+func TestSynchronizedListBulk(t *testing.T) {
+	const n = 100
+
+	list := NewSynchronizedList(0)
+
+	es := make([]interface{}, n)
+	for i := 0; i < n; i++ {
+		es[i] = i
+	}
+	assert.Equal(t, n, list.AddAll(es...))
+	assert.Equal(t, n, list.Size())
+
+	eq := func(l, r interface{}) bool { return l.(int) == r.(int) }
+	assert.Equal(t, 3, list.RemoveAll(eq, 0, 1, 2, n))
+	assert.Equal(t, n-3, list.Size())
+	assert.Equal(t, 3, list.Get(0))
+
+	odd := func(e interface{}) bool { return e.(int)%2 != 0 }
+	assert.Equal(t, 49, list.RemoveIf(odd))
+	list.Range(func(e interface{}) bool {
+		assert.False(t, odd(e))
+		return true
+	})
+
+	small := func(e interface{}) bool { return e.(int) < n/2 }
+	assert.Equal(t, 25, list.RetainAll(small))
+	assert.Equal(t, 23, list.Size())
+	for i := 0; i < list.Size(); i++ {
+		assert.Equal(t, (i+2)*2, list.Get(i))
+	}
+}
+
+func TestSynchronizedListToSlice(t *testing.T) {
+	const n = 100
+
+	list := NewSynchronizedList(0)
+	assert.Empty(t, list.ToSlice())
+
+	for i := 0; i < n; i++ {
+		list.Add(i)
+	}
+
+	s := list.ToSlice()
+	assert.Equal(t, n, len(s))
+	for i := 0; i < n; i++ {
+		assert.Equal(t, i, s[i])
+	}
+
+	list.Clear()
+	list.Add(-1)
+	dst := list.ToSliceInto(s)
+	assert.Equal(t, []interface{}{-1}, dst)
+	assert.Equal(t, &s[0], &dst[0], "Should reuse the destination")
+}
+
+func TestSynchronizedListInsertSetRemoveAt(t *testing.T) {
+	list := NewSynchronizedList(0)
+
+	list.Insert(0, 1)
+	list.Insert(0, 0)
+	list.Insert(2, 3)
+	list.Insert(2, 2)
+	assert.Equal(t, []interface{}{0, 1, 2, 3}, list.ToSlice())
+
+	assert.Equal(t, 2, list.Set(2, 20))
+	assert.Equal(t, []interface{}{0, 1, 20, 3}, list.ToSlice())
+
+	assert.Equal(t, 0, list.RemoveAt(0))
+	assert.Equal(t, 3, list.RemoveAt(2))
+	assert.Equal(t, []interface{}{1, 20}, list.ToSlice())
+
+	assert.Panics(t, func() { list.Insert(3, 0) })
+	assert.Panics(t, func() { list.Insert(-1, 0) })
+	assert.Panics(t, func() { list.Set(2, 0) })
+	assert.Panics(t, func() { list.RemoveAt(2) })
+}
+
+func TestSynchronizedListIndexOf(t *testing.T) {
+	list := NewSynchronizedList(0)
+	list.AddAll(0, 1, 2, 1, 0)
+
+	eq := func(l, r interface{}) bool { return l.(int) == r.(int) }
+	assert.Equal(t, 1, list.IndexOf(1, eq))
+	assert.Equal(t, 3, list.LastIndexOf(1, eq))
+	assert.Equal(t, 2, list.IndexOf(2, eq))
+	assert.Equal(t, 2, list.LastIndexOf(2, eq))
+	assert.Equal(t, -1, list.IndexOf(3, eq))
+	assert.Equal(t, -1, list.LastIndexOf(3, eq))
+	assert.True(t, list.Contains(0, eq))
+	assert.False(t, list.Contains(3, eq))
+}
+
+func TestSynchronizedListSubList(t *testing.T) {
+	list := NewSynchronizedList(0)
+	list.AddAll(0, 1, 2, 3, 4)
+
+	sub := list.SubList(1, 4)
+	assert.Equal(t, []interface{}{1, 2, 3}, sub.ToSlice())
+
+	// the sublist is a snapshot
+	list.Set(1, 10)
+	sub.Add(5)
+	assert.Equal(t, []interface{}{1, 2, 3, 5}, sub.ToSlice())
+	assert.Equal(t, []interface{}{0, 10, 2, 3, 4}, list.ToSlice())
+
+	assert.Equal(t, 0, list.SubList(2, 2).Size())
+	assert.Panics(t, func() { list.SubList(3, 6) })
+}
+
+func TestSynchronizedListTryGet(t *testing.T) {
+	list := NewSynchronizedList(0)
+
+	v, ok := list.TryGet(0)
+	assert.False(t, ok)
+	assert.Nil(t, v)
+	assert.Equal(t, -1, list.GetOrDefault(0, -1))
+
+	list.AddAll(0, 1, 2)
+	v, ok = list.TryGet(2)
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+	_, ok = list.TryGet(3)
+	assert.False(t, ok)
+	_, ok = list.TryGet(-1)
+	assert.False(t, ok)
+	assert.Equal(t, 1, list.GetOrDefault(1, -1))
+	assert.Equal(t, -1, list.GetOrDefault(3, -1))
+}
+
+func TestSynchronizedListFirstLast(t *testing.T) {
+	list := NewSynchronizedList(0)
+
+	for _, f := range []func() (interface{}, bool){list.First, list.Last, list.PopFirst, list.PopLast} {
+		v, ok := f()
+		assert.False(t, ok)
+		assert.Nil(t, v)
+	}
+
+	list.AddAll(0, 1, 2, 3)
+
+	v, ok := list.First()
+	assert.True(t, ok)
+	assert.Equal(t, 0, v)
+	v, ok = list.Last()
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+
+	v, ok = list.PopFirst()
+	assert.True(t, ok)
+	assert.Equal(t, 0, v)
+	v, ok = list.PopLast()
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+	assert.Equal(t, []interface{}{1, 2}, list.ToSlice())
+}
+
+func TestSynchronizedListPopConcurrent(t *testing.T) {
+	const n = 1000
+
+	list := NewSynchronizedList(n)
+	for i := 0; i < n; i++ {
+		list.Add(i)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	popped := 0
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			pop := list.PopFirst
+			if i%2 == 0 {
+				pop = list.PopLast
+			}
+			for {
+				if _, ok := pop(); !ok {
+					return
+				}
+				mu.Lock()
+				popped++
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, n, popped)
+	assert.Equal(t, 0, list.Size())
+}
+
+func TestSynchronizedListSort(t *testing.T) {
+	const n = 100
+
+	list := NewSynchronizedList(n)
+	for i := 0; i < n; i++ {
+		list.Add((i * 37) % n)
+	}
+
+	list.Sort(func(l, r interface{}) bool { return l.(int) < r.(int) })
+	for i := 0; i < n; i++ {
+		assert.Equal(t, i, list.Get(i))
+	}
+
+	type pair struct{ k, v int }
+	list.Clear()
+	for i := 0; i < n; i++ {
+		list.Add(pair{k: i % 3, v: i})
+	}
+	list.SortStable(func(l, r interface{}) bool { return l.(pair).k < r.(pair).k })
+	prev := pair{k: -1}
+	list.Range(func(e interface{}) bool {
+		p := e.(pair)
+		assert.True(t, p.k > prev.k || p.v > prev.v, "Should keep the order of equal elements")
+		prev = p
+		return true
+	})
+}
+
+func TestSynchronizedListBinarySearch(t *testing.T) {
+	cmp := func(l, r interface{}) int { return l.(int) - r.(int) }
+
+	list := NewSynchronizedList(0)
+	i, ok := list.BinarySearch(1, cmp)
+	assert.False(t, ok)
+	assert.Equal(t, 0, i)
+
+	list.AddAll(0, 2, 4, 6, 8)
+	for j := 0; j < 10; j++ {
+		i, ok := list.BinarySearch(j, cmp)
+		assert.Equal(t, j%2 == 0, ok)
+		assert.Equal(t, (j+1)/2, i)
+	}
+}
+
+func TestSynchronizedListInsertSorted(t *testing.T) {
+	const n = 100
+
+	cmp := func(l, r interface{}) int { return l.(int) - r.(int) }
+
+	list := NewSynchronizedList(n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			list.InsertSorted((i*37)%n, cmp)
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < n; i++ {
+		assert.Equal(t, i, list.Get(i))
+	}
+
+	assert.Equal(t, 1, list.InsertSorted(0, cmp))
+	assert.Equal(t, n+1, list.InsertSorted(n, cmp))
+}
+
+func TestSynchronizedListBoundedReject(t *testing.T) {
+	const n = 10
+
+	list := NewBoundedSynchronizedList(n, OverflowReject)
+	assert.Equal(t, n, list.Capacity())
+	assert.Equal(t, 0, NewSynchronizedList(n).Capacity())
+
+	for i := 0; i < n-2; i++ {
+		assert.Nil(t, list.TryAdd(i))
+	}
+	assert.Equal(t, 2, list.AddAll(n, n, n))
+	assert.Equal(t, n, list.Size())
+
+	assert.Equal(t, ErrFull, list.TryAdd(n))
+	assert.Equal(t, ErrFull, list.TryInsert(0, n))
+	_, err := list.TryInsertSorted(n, func(l, r interface{}) int { return l.(int) - r.(int) })
+	assert.Equal(t, ErrFull, err)
+	assert.Equal(t, 0, list.AddAll(n))
+	assert.Equal(t, n, list.Size())
+
+	list.PopFirst()
+	assert.Nil(t, list.TryAdd(n))
+	assert.Equal(t, n, list.Size())
+	assert.Equal(t, 1, list.Get(0))
+}
+
+func TestSynchronizedListBoundedDropOldest(t *testing.T) {
+	const n = 10
+
+	list := NewBoundedSynchronizedList(n, OverflowDropOldest)
+	for i := 0; i < n*2; i++ {
+		assert.Nil(t, list.TryAdd(i))
+	}
+	assert.Equal(t, n, list.Size())
+	for i := 0; i < n; i++ {
+		assert.Equal(t, i+n, list.Get(i))
+	}
+
+	assert.Nil(t, list.TryInsert(n, -1))
+	assert.Equal(t, n, list.Size())
+	assert.Equal(t, n+1, list.Get(0))
+	assert.Equal(t, -1, list.Get(n-1))
+
+	cmp := func(l, r interface{}) int { return l.(int) - r.(int) }
+	list.Clear()
+	list.AddAll(0, 2, 4, 6, 8, 10, 12, 14, 16, 18)
+	i, err := list.TryInsertSorted(5, cmp)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, i)
+	assert.Equal(t, []interface{}{2, 4, 5, 6, 8, 10, 12, 14, 16, 18}, list.ToSlice())
+
+	i, err = list.TryInsertSorted(1, cmp)
+	assert.Equal(t, ErrFull, err, "Should be dropped")
+	assert.Equal(t, -1, i)
+	assert.Equal(t, 2, list.Get(0))
+}
+
+func TestSynchronizedListAddWait(t *testing.T) {
+	const n = 10
+
+	list := NewBoundedSynchronizedList(n, OverflowReject)
+	for i := 0; i < n; i++ {
+		list.Add(i)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, list.AddWait(ctx, n))
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			assert.Nil(t, list.AddWait(context.Background(), n+i))
+			wg.Done()
+		}(i)
+	}
+
+	removed := 0
+	for removed < n {
+		if _, ok := list.PopFirst(); ok {
+			removed++
+		}
+	}
+	wg.Wait()
+
+	assert.Equal(t, n, list.Size())
+	list.Range(func(e interface{}) bool {
+		assert.True(t, e.(int) >= n)
+		return true
+	})
+}
+
+func TestSynchronizedListVersion(t *testing.T) {
+	list := NewSynchronizedList(0)
+	eq := func(l, r interface{}) bool { return l.(int) == r.(int) }
+	less := func(l, r interface{}) bool { return l.(int) < r.(int) }
+
+	v := list.Version()
+	for _, f := range []func(){
+		func() { list.Add(0) },
+		func() { list.AddAll(1, 2) },
+		func() { list.Insert(0, 3) },
+		func() { list.Set(0, 4) },
+		func() { list.Sort(less) },
+		func() { list.SortStable(less) },
+		func() { list.RemoveAt(0) },
+		func() { list.Remove(1, eq) },
+		func() { list.PopFirst() },
+		func() { list.RemoveIf(func(interface{}) bool { return true }) },
+		func() { list.Clear() },
+	} {
+		f()
+		assert.True(t, list.Version() > v, "Version should move")
+		v = list.Version()
+	}
+
+	list.TryGet(0)
+	list.ToSlice()
+	list.RemoveIf(func(interface{}) bool { return false })
+	assert.Equal(t, v, list.Version(), "Version should not move")
+}
+
+func TestSynchronizedListIterator(t *testing.T) {
+	const n = 10
+
+	list := NewSynchronizedList(0)
+	for i := 0; i < n; i++ {
+		list.Add(i)
+	}
+
+	it := list.Iterator(true)
+	i := 0
+	for it.Next() {
+		assert.Equal(t, i, it.Value())
+		i++
+	}
+	assert.Equal(t, n, i)
+	assert.Nil(t, it.Err())
+
+	it = list.Iterator(true)
+	assert.True(t, it.Next())
+	list.Add(n)
+	assert.False(t, it.Next())
+	assert.Equal(t, ErrConcurrentModification, it.Err())
+
+	it = list.Iterator(false)
+	list.Clear()
+	i = 0
+	for it.Next() {
+		i++
+	}
+	assert.Equal(t, n+1, i)
+	assert.Nil(t, it.Err())
+}
+
+func TestSynchronizedListBoundedDropNewest(t *testing.T) {
+	const n = 10
+
+	list := NewBoundedSynchronizedList(n, OverflowDropNewest)
+	for i := 0; i < n; i++ {
+		assert.Nil(t, list.TryAdd(i))
+	}
+	for i := n; i < n*2; i++ {
+		assert.Equal(t, ErrFull, list.TryAdd(i))
+	}
+	assert.Equal(t, n, list.Size())
+	for i := 0; i < n; i++ {
+		assert.Equal(t, i, list.Get(i))
+	}
+
+	assert.Equal(t, ErrFull, list.TryInsert(0, -1))
+	assert.Equal(t, 0, list.AddAll(n))
+	list.Add(n)
+	assert.Equal(t, []interface{}{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, list.ToSlice())
+}
+
+func TestSynchronizedListBoundedBlock(t *testing.T) {
+	const n = 10
+
+	list := NewBoundedSynchronizedList(n, OverflowBlock)
+	assert.Equal(t, n, list.AddAll(0, 1, 2, 3, 4, 5, 6, 7, 8, 9))
+
+	done := make(chan struct{})
+	go func() {
+		assert.Nil(t, list.TryAdd(n))
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Add should block")
+	case <-time.After(10 * time.Millisecond):
+	}
+	list.RemoveAt(0)
+	<-done
+	assert.Equal(t, n, list.Size())
+	v, _ := list.Last()
+	assert.Equal(t, n, v)
+}
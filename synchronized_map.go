@@ -7,7 +7,8 @@ import (
 // SynchronizedMap is a safe for concurrent use Map implementation.
 type SynchronizedMap struct {
 	sync.RWMutex
-	data map[interface{}]interface{}
+	data    map[interface{}]interface{}
+	version uint64
 }
 
 // NewSynchronizedMap returns pointer to a new SynchronizedMap instance.
@@ -29,6 +30,7 @@ func (m *SynchronizedMap) Size() int {
 func (m *SynchronizedMap) Clear() {
 	m.Lock()
 	m.data = make(map[interface{}]interface{})
+	m.version++
 	m.Unlock()
 }
 
@@ -37,6 +39,7 @@ func (m *SynchronizedMap) Put(k interface{}, v interface{}) interface{} {
 	m.Lock()
 	o, _ := m.data[k]
 	m.data[k] = v
+	m.version++
 	m.Unlock()
 	return o
 }
@@ -49,6 +52,7 @@ func (m *SynchronizedMap) PutIfAbsent(k interface{}, v interface{}) bool {
 		return false
 	}
 	m.data[k] = v
+	m.version++
 	m.Unlock()
 	return true
 }
@@ -66,6 +70,7 @@ func (m *SynchronizedMap) ComputeIfAbsent(k interface{}, f func() interface{}) (
 		return nil, false
 	}
 	m.data[k] = v
+	m.version++
 	return v, true
 }
 
@@ -99,7 +104,10 @@ func (m *SynchronizedMap) Range(f func(k, v interface{}) bool) {
 // Remove implements Map.Remove.
 func (m *SynchronizedMap) Remove(k interface{}) {
 	m.Lock()
-	delete(m.data, k)
+	if _, ok := m.data[k]; ok {
+		delete(m.data, k)
+		m.version++
+	}
 	m.Unlock()
 }
 
@@ -114,3 +122,104 @@ func (m *SynchronizedMap) Keys() []interface{} {
 	}
 	return r
 }
+
+// PutAll implements Map.PutAll.
+func (m *SynchronizedMap) PutAll(src map[interface{}]interface{}) int {
+	m.Lock()
+	for k, v := range src {
+		m.data[k] = v
+	}
+	if len(src) > 0 {
+		m.version++
+	}
+	m.Unlock()
+	return len(src)
+}
+
+// RemoveAll implements Map.RemoveAll.
+func (m *SynchronizedMap) RemoveAll(ks ...interface{}) int {
+	m.Lock()
+	defer m.Unlock()
+	n := len(m.data)
+	for _, k := range ks {
+		delete(m.data, k)
+	}
+	r := n - len(m.data)
+	if r > 0 {
+		m.version++
+	}
+	return r
+}
+
+// RetainAll implements Map.RetainAll.
+func (m *SynchronizedMap) RetainAll(f func(k, v interface{}) bool) int {
+	return m.RemoveIf(func(k, v interface{}) bool { return !f(k, v) })
+}
+
+// RemoveIf implements Map.RemoveIf.
+func (m *SynchronizedMap) RemoveIf(f func(k, v interface{}) bool) int {
+	m.Lock()
+	defer m.Unlock()
+	n := len(m.data)
+	for k, v := range m.data {
+		if f(k, v) {
+			delete(m.data, k)
+		}
+	}
+	r := n - len(m.data)
+	if r > 0 {
+		m.version++
+	}
+	return r
+}
+
+// Values implements Map.Values.
+func (m *SynchronizedMap) Values() []interface{} {
+	m.RLock()
+	defer m.RUnlock()
+	r := make([]interface{}, 0, len(m.data))
+	for _, v := range m.data {
+		r = append(r, v)
+	}
+	return r
+}
+
+// Entries implements Map.Entries.
+func (m *SynchronizedMap) Entries() []Entry {
+	m.RLock()
+	defer m.RUnlock()
+	r := make([]Entry, 0, len(m.data))
+	for k, v := range m.data {
+		r = append(r, Entry{Key: k, Value: v})
+	}
+	return r
+}
+
+// ToMap implements Map.ToMap.
+func (m *SynchronizedMap) ToMap() map[interface{}]interface{} {
+	m.RLock()
+	defer m.RUnlock()
+	r := make(map[interface{}]interface{}, len(m.data))
+	for k, v := range m.data {
+		r[k] = v
+	}
+	return r
+}
+
+// Version implements Map.Version.
+func (m *SynchronizedMap) Version() uint64 {
+	m.RLock()
+	defer m.RUnlock()
+	return m.version
+}
+
+// Iterator implements Map.Iterator.
+func (m *SynchronizedMap) Iterator(failFast bool) Iterator {
+	m.RLock()
+	defer m.RUnlock()
+	data := make([]interface{}, 0, len(m.data))
+	for k, v := range m.data {
+		data = append(data, Entry{Key: k, Value: v})
+	}
+	return newSnapshotIterator(m, data, m.version, failFast)
+}
@@ -322,3 +322,126 @@ func TestSynchronizedMapKeys(t *testing.T) {
 	keys := m.Keys()
 	assert.Equal(t, n*n, len(keys))
 }
+
+func TestSynchronizedMapBulk(t *testing.T) {
+	const n = 100
+
+	m := NewSynchronizedMap(0)
+
+	src := make(map[interface{}]interface{}, n)
+	for i := 0; i < n; i++ {
+		src[i] = i * i
+	}
+	assert.Equal(t, n, m.PutAll(src))
+	assert.Equal(t, n, m.Size())
+	for i := 0; i < n; i++ {
+		assert.Equal(t, i*i, m.Get(i))
+	}
+
+	assert.Equal(t, 3, m.RemoveAll(0, 1, 2, n))
+	assert.Equal(t, n-3, m.Size())
+
+	odd := func(k, v interface{}) bool { return k.(int)%2 != 0 }
+	assert.Equal(t, 49, m.RemoveIf(odd))
+	m.Range(func(k, v interface{}) bool {
+		assert.False(t, odd(k, v))
+		return true
+	})
+
+	small := func(k, v interface{}) bool { return v.(int) < n*n/4 }
+	assert.Equal(t, 25, m.RetainAll(small))
+	assert.Equal(t, 23, m.Size())
+	for i := 4; i < n/2; i += 2 {
+		assert.True(t, m.Contains(i))
+	}
+}
+
+func TestSynchronizedMapValuesEntries(t *testing.T) {
+	const n = 100
+
+	m := NewSynchronizedMap(0)
+	assert.Empty(t, m.Values())
+	assert.Empty(t, m.Entries())
+	assert.Empty(t, m.ToMap())
+
+	for i := 0; i < n; i++ {
+		m.Put(i, i*i)
+	}
+
+	var values []int
+	for _, v := range m.Values() {
+		values = append(values, v.(int))
+	}
+	sort.Ints(values)
+	for i := 0; i < n; i++ {
+		assert.Equal(t, i*i, values[i])
+	}
+
+	entries := m.Entries()
+	assert.Equal(t, n, len(entries))
+	for _, e := range entries {
+		assert.Equal(t, e.Key.(int)*e.Key.(int), e.Value)
+	}
+
+	c := m.ToMap()
+	assert.Equal(t, n, len(c))
+	m.Clear()
+	for i := 0; i < n; i++ {
+		assert.Equal(t, i*i, c[i])
+	}
+}
+
+func TestSynchronizedMapVersion(t *testing.T) {
+	m := NewSynchronizedMap(0)
+
+	v := m.Version()
+	for _, f := range []func(){
+		func() { m.Put(0, 0) },
+		func() { m.Put(0, 1) },
+		func() { m.PutIfAbsent(1, 1) },
+		func() { m.ComputeIfAbsent(2, func() interface{} { return 2 }) },
+		func() { m.PutAll(map[interface{}]interface{}{3: 3}) },
+		func() { m.Remove(0) },
+		func() { m.RemoveAll(1) },
+		func() { m.RemoveIf(func(k, v interface{}) bool { return k == 2 }) },
+		func() { m.Clear() },
+	} {
+		f()
+		assert.True(t, m.Version() > v, "Version should move")
+		v = m.Version()
+	}
+
+	m.Put(0, 0)
+	v = m.Version()
+	m.PutIfAbsent(0, 1)
+	m.ComputeIfAbsent(0, func() interface{} { return 1 })
+	m.Remove(1)
+	m.RemoveAll(1)
+	m.Get(0)
+	assert.Equal(t, v, m.Version(), "Version should not move")
+}
+
+func TestSynchronizedMapIterator(t *testing.T) {
+	const n = 10
+
+	m := NewSynchronizedMap(0)
+	for i := 0; i < n; i++ {
+		m.Put(i, i*i)
+	}
+
+	cnt := 0
+	it := m.Iterator(true)
+	for it.Next() {
+		e := it.Value().(Entry)
+		assert.Equal(t, e.Key.(int)*e.Key.(int), e.Value)
+		cnt++
+	}
+	assert.Nil(t, it.Err())
+	assert.Equal(t, n, cnt)
+
+	it = m.Iterator(true)
+	assert.True(t, it.Next())
+	m.Put(0, 1)
+	assert.False(t, it.Next())
+	assert.Equal(t, ErrConcurrentModification, it.Err())
+}
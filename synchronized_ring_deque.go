@@ -0,0 +1,109 @@
+package concurrent
+
+// SynchronizedRingDeque is a safe for concurrent use Deque implementation
+type SynchronizedRingDeque struct {
+	SynchronizedRingQueue
+}
+
+// NewSynchronizedRingDeque returns pointer to a new SynchronizedRingDeque
+// instance
+func NewSynchronizedRingDeque(initialCapacity int) *SynchronizedRingDeque {
+	d := &SynchronizedRingDeque{}
+	d.buf = newRingBuffer(initialCapacity)
+	d.minCapacity = initialCapacity
+	d.shrinkPolls = defaultShrinkPolls
+	return d
+}
+
+// OfferFirst implements Deque.OfferFirst
+func (d *SynchronizedRingDeque) OfferFirst(e interface{}) {
+	d.TryOfferFirst(e)
+}
+
+// OfferLast implements Deque.OfferLast
+func (d *SynchronizedRingDeque) OfferLast(e interface{}) {
+	d.Offer(e)
+}
+
+// TryOfferFirst implements Deque.TryOfferFirst
+func (d *SynchronizedRingDeque) TryOfferFirst(e interface{}) bool {
+	d.Lock()
+	defer d.Unlock()
+
+	if d.closed {
+		return false
+	}
+
+	if d.count == len(d.buf) {
+		d.resize(len(d.buf) << 1)
+	}
+
+	d.head = (d.head - 1) & (len(d.buf) - 1)
+	d.buf[d.head] = e
+	d.count++
+	d.version++
+	d.pushedFirst++
+	d.notEmpty.broadcast()
+	return true
+}
+
+// TryOfferLast implements Deque.TryOfferLast
+func (d *SynchronizedRingDeque) TryOfferLast(e interface{}) bool {
+	return d.TryOffer(e)
+}
+
+// PollFirst implements Deque.PollFirst
+func (d *SynchronizedRingDeque) PollFirst() interface{} {
+	return d.Poll()
+}
+
+// PollLast implements Deque.PollLast
+func (d *SynchronizedRingDeque) PollLast() interface{} {
+	d.Lock()
+	defer d.Unlock()
+
+	if d.count <= 0 {
+		return nil
+	}
+
+	d.tail = (d.tail - 1) & (len(d.buf) - 1)
+	ret := d.buf[d.tail]
+	d.buf[d.tail] = nil
+	d.count--
+	d.version++
+	d.notFull.broadcast()
+	d.shrink()
+	return ret
+}
+
+// PeekFirst implements Deque.PeekFirst
+func (d *SynchronizedRingDeque) PeekFirst() interface{} {
+	return d.Peek()
+}
+
+// PeekLast implements Deque.PeekLast
+func (d *SynchronizedRingDeque) PeekLast() interface{} {
+	d.RLock()
+	defer d.RUnlock()
+
+	if d.count <= 0 {
+		return nil
+	}
+
+	return d.buf[(d.tail-1)&(len(d.buf)-1)]
+}
+
+// RangeReverse implements Deque.RangeReverse
+func (d *SynchronizedRingDeque) RangeReverse(f func(e interface{}) bool) {
+	d.RLock()
+	defer d.RUnlock()
+
+	m := len(d.buf) - 1
+	t := d.tail
+	for i := 0; i < d.count; i++ {
+		t = (t - 1) & m
+		if !f(d.buf[t]) {
+			return
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package concurrent
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSynchronizedRingDequeOfferPoll(t *testing.T) {
+	for c := 2; c < 64; c <<= 1 {
+		t.Run("should offer and poll at both ends", func(t *testing.T) {
+			d := NewSynchronizedRingDeque(c)
+			assert.Nil(t, d.PollFirst())
+			assert.Nil(t, d.PollLast())
+			assert.Nil(t, d.PeekFirst())
+			assert.Nil(t, d.PeekLast())
+
+			// -c..-1 at the head, 0..c at the tail
+			for i := 0; i <= c; i++ {
+				d.OfferLast(i)
+				if i > 0 {
+					d.OfferFirst(-i)
+				}
+			}
+			assert.Equal(t, 2*c+1, d.Size())
+			assert.Equal(t, 4*c, d.Capacity())
+
+			assert.Equal(t, -c, d.PeekFirst())
+			assert.Equal(t, c, d.PeekLast())
+
+			for i := -c; i < 0; i++ {
+				assert.Equal(t, i, d.PollFirst())
+			}
+			for i := c; i >= 0; i-- {
+				assert.Equal(t, i, d.PeekLast())
+				assert.Equal(t, i, d.PollLast())
+			}
+			assert.Equal(t, 0, d.Size())
+			assert.Nil(t, d.PollLast())
+		})
+	}
+}
+
+func TestSynchronizedRingDequeRange(t *testing.T) {
+	const n = 100
+
+	d := NewSynchronizedRingDeque(2)
+	for i := 0; i < n; i++ {
+		d.OfferFirst(i)
+	}
+
+	var b []int
+	d.Range(func(e interface{}) bool {
+		b = append(b, e.(int))
+		return true
+	})
+	var r []int
+	d.RangeReverse(func(e interface{}) bool {
+		r = append(r, e.(int))
+		return len(r) < n/2
+	})
+
+	assert.Equal(t, n, len(b))
+	assert.Equal(t, n/2, len(r))
+	for i := 0; i < n; i++ {
+		assert.Equal(t, n-1-i, b[i])
+	}
+	for i := 0; i < n/2; i++ {
+		assert.Equal(t, i, r[i])
+	}
+}
+
+func TestSynchronizedRingDequeConcurrent(t *testing.T) {
+	const n = 1000
+
+	d := NewSynchronizedRingDeque(2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			for j := 0; j < n; j++ {
+				if i%2 == 0 {
+					d.OfferFirst(j)
+				} else {
+					d.OfferLast(j)
+				}
+			}
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 4*n, d.Size())
+
+	var mu sync.Mutex
+	polled := 0
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			for {
+				var e interface{}
+				if i%2 == 0 {
+					e = d.PollFirst()
+				} else {
+					e = d.PollLast()
+				}
+				if e == nil {
+					break
+				}
+				mu.Lock()
+				polled++
+				mu.Unlock()
+			}
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 4*n, polled)
+}
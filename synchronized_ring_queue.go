@@ -1,23 +1,68 @@
 package concurrent
 
 import (
+	"context"
 	"sync"
+	"time"
 )
 
-// SynchronizedRingQueue is a safe for concurrent use Queue implementation
+// SynchronizedRingQueue is a safe for concurrent use BlockingQueue
+// implementation
 type SynchronizedRingQueue struct {
 	sync.RWMutex
 	buf               []interface{}
 	head, tail, count int
+	version           uint64
+	closed            bool
+	notEmpty          cond
+	notFull           cond
+	bounded           bool
+	policy            OverflowPolicy
+	onDrop            func(e interface{})
+	stats             OverflowStats
+	minCapacity       int
+	shrinkPolls       int
+	lowPolls          int
+	headGen           uint64
+	pushedFirst       uint64
 }
 
-// NewSynchronizedRingQueue returns pointer to a new SynchronizedRingQueue instance
+// ringHead identifies the head of a SynchronizedRingQueue for the channel
+// pump. The head generation changes whenever an element is removed from the
+// head; the elements inserted at the head of a SynchronizedRingDeque are
+// counted separately, so they only move the element away from the head.
+type ringHead struct {
+	gen         uint64
+	pushedFirst uint64
+}
+
+// defaultShrinkPolls is the default number of consecutive polls with low
+// occupancy after which the queue buffer is halved.
+const defaultShrinkPolls = 64
+
+// NewSynchronizedRingQueue returns pointer to a new SynchronizedRingQueue instance.
+// The queue grows when it is full and shrinks back to its initial capacity
+// when it stays drained, see SetShrinkPolicy.
 func NewSynchronizedRingQueue(initialCapacity int) *SynchronizedRingQueue {
-	if (initialCapacity < 2) || ((initialCapacity & (initialCapacity - 1)) != 0) {
-		panic("initial capacity must be power of 2")
+	return &SynchronizedRingQueue{
+		buf:         newRingBuffer(initialCapacity),
+		minCapacity: initialCapacity,
+		shrinkPolls: defaultShrinkPolls,
 	}
+}
+
+// NewBoundedSynchronizedRingQueue returns pointer to a new SynchronizedRingQueue
+// instance which may not contain more than capacity elements. The capacity
+// must be power of 2. The policy defines how Offer and TryOffer handle a new
+// element when the queue is full: OverflowReject makes TryOffer return false,
+// OverflowDropOldest removes the head of the queue, OverflowDropNewest
+// discards the new element and makes TryOffer return false, OverflowBlock
+// makes them wait for room. Put and OfferTimeout always wait for room.
+func NewBoundedSynchronizedRingQueue(capacity int, policy OverflowPolicy) *SynchronizedRingQueue {
 	return &SynchronizedRingQueue{
-		buf: make([]interface{}, initialCapacity),
+		buf:     newRingBuffer(capacity),
+		bounded: true,
+		policy:  policy,
 	}
 }
 
@@ -32,39 +77,213 @@ func (q *SynchronizedRingQueue) Size() int {
 func (q *SynchronizedRingQueue) Clear() {
 	q.Lock()
 	defer q.Unlock()
+	if !q.bounded && len(q.buf) > q.minCapacity {
+		q.buf = make([]interface{}, q.minCapacity)
+	} else {
+		q.clearRange(q.head, q.count)
+	}
 	q.head = 0
 	q.tail = 0
 	q.count = 0
+	q.lowPolls = 0
+	q.version++
+	q.headGen++
+	q.notFull.broadcast()
 }
 
 // Offer implements Queue.Offer
 func (q *SynchronizedRingQueue) Offer(e interface{}) {
+	q.TryOffer(e)
+}
+
+// TryOffer implements Queue.TryOffer
+func (q *SynchronizedRingQueue) TryOffer(e interface{}) bool {
 	q.Lock()
 	defer q.Unlock()
-
-	if q.count == len(q.buf) {
-		q.resize()
+	if q.policy == OverflowBlock {
+		return q.put(context.Background(), e) == nil
 	}
-
-	q.buf[q.tail] = e
-	q.tail = (q.tail + 1) & (len(q.buf) - 1)
-	q.count++
+	return q.offer(e)
 }
 
 // Poll implements Queue.Poll
 func (q *SynchronizedRingQueue) Poll() interface{} {
 	q.Lock()
 	defer q.Unlock()
+	return q.poll()
+}
 
-	if q.count <= 0 {
-		return nil
+// Drain implements Queue.Drain
+func (q *SynchronizedRingQueue) Drain(max int, f func(e interface{})) int {
+	q.Lock()
+	n := q.count
+	if max > 0 && max < n {
+		n = max
 	}
+	buf := make([]interface{}, n)
+	q.drainTo(buf)
+	q.Unlock()
 
-	ret := q.buf[q.head]
-	q.buf[q.head] = nil
-	q.head = (q.head + 1) & (len(q.buf) - 1)
-	q.count--
-	return ret
+	for _, e := range buf {
+		f(e)
+	}
+	return n
+}
+
+// DrainTo implements Queue.DrainTo
+func (q *SynchronizedRingQueue) DrainTo(dst []interface{}) int {
+	q.Lock()
+	defer q.Unlock()
+	return q.drainTo(dst)
+}
+
+// OfferAll implements Queue.OfferAll
+func (q *SynchronizedRingQueue) OfferAll(es ...interface{}) int {
+	q.Lock()
+	defer q.Unlock()
+
+	if q.closed || len(es) == 0 {
+		return 0
+	}
+
+	if q.bounded {
+		n := 0
+		for _, e := range es {
+			if q.policy == OverflowBlock {
+				if q.put(context.Background(), e) != nil {
+					break
+				}
+			} else if !q.offer(e) {
+				continue
+			}
+			n++
+		}
+		return n
+	}
+
+	if c := q.count + len(es); c > len(q.buf) {
+		n := len(q.buf)
+		for n < c {
+			n <<= 1
+		}
+		q.resize(n)
+	}
+
+	m := len(q.buf) - 1
+	n := copy(q.buf[q.tail:], es)
+	copy(q.buf, es[n:])
+	q.tail = (q.tail + len(es)) & m
+	q.count += len(es)
+	q.version++
+	q.notEmpty.broadcast()
+	return len(es)
+}
+
+// Put implements BlockingQueue.Put
+func (q *SynchronizedRingQueue) Put(ctx context.Context, e interface{}) error {
+	q.Lock()
+	defer q.Unlock()
+	return q.put(ctx, e)
+}
+
+// Take implements BlockingQueue.Take
+func (q *SynchronizedRingQueue) Take(ctx context.Context) (interface{}, error) {
+	q.Lock()
+	defer q.Unlock()
+	for q.count <= 0 {
+		if q.closed {
+			return nil, ErrClosed
+		}
+		if err := q.notEmpty.wait(ctx, q); err != nil {
+			return nil, err
+		}
+	}
+	return q.poll(), nil
+}
+
+// OfferTimeout implements BlockingQueue.OfferTimeout
+func (q *SynchronizedRingQueue) OfferTimeout(e interface{}, d time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return q.Put(ctx, e) == nil
+}
+
+// PollTimeout implements BlockingQueue.PollTimeout
+func (q *SynchronizedRingQueue) PollTimeout(d time.Duration) (interface{}, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	e, err := q.Take(ctx)
+	return e, err == nil
+}
+
+// Close implements BlockingQueue.Close
+func (q *SynchronizedRingQueue) Close() {
+	q.Lock()
+	defer q.Unlock()
+	q.closed = true
+	q.notEmpty.broadcast()
+	q.notFull.broadcast()
+}
+
+// Out returns a channel which receives the elements retrieved from the queue.
+// The channel is closed when ctx is done, or when the queue is closed and all
+// its elements have been received. An element is removed from the queue only
+// after it has been received from the channel, so the channel must be the
+// only consumer of the queue.
+func (q *SynchronizedRingQueue) Out(ctx context.Context) <-chan interface{} {
+	return pump(ctx, q, q.peekWait)
+}
+
+// SetShrinkPolicy configures shrinking of an unbounded queue. The buffer is
+// halved after the occupancy has stayed below 25% for the number of
+// consecutive polls, but it never gets smaller than minCapacity, which must be
+// power of 2. If polls is not positive, the queue shrinks only on Clear and
+// TrimToSize. By default the queue shrinks after 64 polls down to its initial
+// capacity.
+func (q *SynchronizedRingQueue) SetShrinkPolicy(minCapacity, polls int) {
+	if !isPowerOfTwo(minCapacity) {
+		panic("minCapacity must be power of 2")
+	}
+	q.Lock()
+	defer q.Unlock()
+	q.minCapacity = minCapacity
+	q.shrinkPolls = polls
+	q.lowPolls = 0
+}
+
+// TrimToSize shrinks the buffer of an unbounded queue to the smallest power
+// of 2 which fits the elements, but not below the minimum capacity.
+func (q *SynchronizedRingQueue) TrimToSize() {
+	q.Lock()
+	defer q.Unlock()
+	if q.bounded {
+		return
+	}
+	n := q.minCapacity
+	for n < q.count {
+		n <<= 1
+	}
+	if n < len(q.buf) {
+		q.resize(n)
+	}
+	q.lowPolls = 0
+}
+
+// SetDropHandler sets the function which is called with each element dropped
+// by the OverflowDropOldest and OverflowDropNewest policies. The function is
+// called with the queue locked and must not call the queue methods.
+func (q *SynchronizedRingQueue) SetDropHandler(f func(e interface{})) {
+	q.Lock()
+	q.onDrop = f
+	q.Unlock()
+}
+
+// OverflowStats returns the numbers of the elements affected by the overflow
+// policy.
+func (q *SynchronizedRingQueue) OverflowStats() OverflowStats {
+	q.RLock()
+	defer q.RUnlock()
+	return q.stats
 }
 
 // Peek implements Queue.Peek
@@ -106,17 +325,299 @@ func (q *SynchronizedRingQueue) Range(f func(e interface{}) bool) {
 	}
 }
 
-func (q *SynchronizedRingQueue) resize() {
-	newBuf := make([]interface{}, q.count<<1)
+// RemoveAll removes all the occurrences of the elements es from the queue
+// preserving the order of the remaining ones. Returns the number of the
+// elements removed.
+func (q *SynchronizedRingQueue) RemoveAll(eq Equals, es ...interface{}) int {
+	return q.RemoveIf(func(o interface{}) bool {
+		for _, e := range es {
+			if eq(e, o) {
+				return true
+			}
+		}
+		return false
+	})
+}
 
-	if q.tail > q.head {
-		copy(newBuf, q.buf[q.head:q.tail])
-	} else {
-		n := copy(newBuf, q.buf[q.head:])
-		copy(newBuf[n:], q.buf[:q.tail])
+// RetainAll removes all the elements which do not satisfy the predicate f.
+// Returns the number of the elements removed.
+func (q *SynchronizedRingQueue) RetainAll(f func(e interface{}) bool) int {
+	return q.RemoveIf(func(e interface{}) bool { return !f(e) })
+}
+
+// RemoveIf removes all the elements which satisfy the predicate f preserving
+// the order of the remaining ones. Returns the number of the elements removed.
+func (q *SynchronizedRingQueue) RemoveIf(f func(e interface{}) bool) int {
+	q.Lock()
+	defer q.Unlock()
+
+	m := len(q.buf) - 1
+	r, w, kept := q.head, q.head, 0
+	for i := 0; i < q.count; i++ {
+		e := q.buf[r]
+		if !f(e) {
+			q.buf[w] = e
+			w = (w + 1) & m
+			kept++
+		}
+		r = (r + 1) & m
+	}
+
+	n := q.count - kept
+	if n == 0 {
+		return 0
+	}
+	q.clearRange(w, n)
+	q.tail = w
+	q.count -= n
+	q.version++
+	q.headGen++
+	q.notFull.broadcast()
+	return n
+}
+
+// ToSlice implements Queue.ToSlice
+func (q *SynchronizedRingQueue) ToSlice() []interface{} {
+	return q.ToSliceInto(nil)
+}
+
+// ToSliceInto implements Queue.ToSliceInto
+func (q *SynchronizedRingQueue) ToSliceInto(dst []interface{}) []interface{} {
+	q.RLock()
+	defer q.RUnlock()
+	return q.copyTo(dst)
+}
+
+// Version returns the number of modifications made to the queue.
+func (q *SynchronizedRingQueue) Version() uint64 {
+	q.RLock()
+	defer q.RUnlock()
+	return q.version
+}
+
+// Iterator returns an iterator over a snapshot of the queue in FIFO order.
+// If failFast is true, the iterator stops with ErrConcurrentModification once
+// the queue is modified.
+func (q *SynchronizedRingQueue) Iterator(failFast bool) Iterator {
+	q.RLock()
+	defer q.RUnlock()
+	return newSnapshotIterator(q, q.copyTo(nil), q.version, failFast)
+}
+
+// drainTo removes up to len(dst) elements from the head of the queue and
+// stores them into dst.
+func (q *SynchronizedRingQueue) drainTo(dst []interface{}) int {
+	n := q.count
+	if len(dst) < n {
+		n = len(dst)
+	}
+	if n == 0 {
+		return 0
+	}
+
+	c := copy(dst[:n], q.buf[q.head:])
+	copy(dst[c:n], q.buf)
+	q.clearRange(q.head, n)
+	q.head = (q.head + n) & (len(q.buf) - 1)
+	q.count -= n
+	q.version++
+	q.notFull.broadcast()
+	q.shrink()
+	return n
+}
+
+// peekWait waits until the queue is not empty and returns the handle and the
+// element of its head without removing it. Returns ErrClosed if the queue is
+// closed and empty, or the context error if ctx is done.
+func (q *SynchronizedRingQueue) peekWait(ctx context.Context) (interface{}, interface{}, error) {
+	q.Lock()
+	defer q.Unlock()
+	for q.count <= 0 {
+		if q.closed {
+			return nil, nil, ErrClosed
+		}
+		if err := q.notEmpty.wait(ctx, q); err != nil {
+			return nil, nil, err
+		}
+	}
+	return ringHead{q.headGen, q.pushedFirst}, q.buf[q.head], nil
+}
+
+// peekHead returns the handle and the element of the head of the queue.
+func (q *SynchronizedRingQueue) peekHead() (interface{}, interface{}) {
+	q.RLock()
+	defer q.RUnlock()
+	if q.count <= 0 {
+		return nil, nil
+	}
+	return ringHead{q.headGen, q.pushedFirst}, q.buf[q.head]
+}
+
+// removeHead removes the element identified by the handle h. Returns false
+// if the element has been removed, e.g. dropped by OverflowDropOldest.
+func (q *SynchronizedRingQueue) removeHead(h interface{}) bool {
+	q.Lock()
+	defer q.Unlock()
+	rh := h.(ringHead)
+	i := int(q.pushedFirst - rh.pushedFirst)
+	if rh.gen != q.headGen || i >= q.count {
+		return false
+	}
+	// move the elements inserted at the head meanwhile over the element
+	m := len(q.buf) - 1
+	for ; i > 0; i-- {
+		j := (q.head + i) & m
+		q.buf[j] = q.buf[(j-1)&m]
+	}
+	q.poll()
+	return true
+}
+
+// put waits until the queue has room and inserts the element e.
+func (q *SynchronizedRingQueue) put(ctx context.Context, e interface{}) error {
+	for q.full() && !q.closed {
+		if err := q.notFull.wait(ctx, q); err != nil {
+			return err
+		}
+	}
+	if !q.offer(e) {
+		return ErrClosed
+	}
+	return nil
+}
+
+// offer inserts the element e at the tail of the queue applying the overflow
+// policy and wakes up the waiting consumers. Returns false if the queue is
+// closed or the element has been rejected or dropped.
+func (q *SynchronizedRingQueue) offer(e interface{}) bool {
+	if q.closed {
+		return false
+	}
+
+	if q.count == len(q.buf) {
+		if !q.bounded {
+			q.resize(len(q.buf) << 1)
+		} else {
+			switch q.policy {
+			case OverflowDropOldest:
+				q.stats.DroppedOldest++
+				q.drop(q.poll())
+			case OverflowDropNewest:
+				q.stats.DroppedNewest++
+				q.drop(e)
+				return false
+			default:
+				q.stats.Rejected++
+				return false
+			}
+		}
+	}
+
+	q.buf[q.tail] = e
+	q.tail = (q.tail + 1) & (len(q.buf) - 1)
+	q.count++
+	q.version++
+	q.notEmpty.broadcast()
+	return true
+}
+
+// poll removes and returns the head of the queue, or nil if the queue is
+// empty.
+func (q *SynchronizedRingQueue) poll() interface{} {
+	if q.count <= 0 {
+		return nil
+	}
+
+	ret := q.buf[q.head]
+	q.buf[q.head] = nil
+	q.head = (q.head + 1) & (len(q.buf) - 1)
+	q.count--
+	q.version++
+	q.headGen++
+	q.notFull.broadcast()
+	q.shrink()
+	return ret
+}
+
+// shrink halves the buffer if the occupancy has stayed below 25% for the
+// configured number of consecutive polls.
+func (q *SynchronizedRingQueue) shrink() {
+	if q.bounded || q.shrinkPolls <= 0 || len(q.buf) <= q.minCapacity {
+		return
+	}
+	if q.count >= len(q.buf)>>2 {
+		q.lowPolls = 0
+		return
+	}
+	if q.lowPolls++; q.lowPolls >= q.shrinkPolls {
+		q.resize(len(q.buf) >> 1)
+		q.lowPolls = 0
+	}
+}
+
+// clearRange nils n elements of the buffer starting from the position i.
+func (q *SynchronizedRingQueue) clearRange(i, n int) {
+	m := len(q.buf) - 1
+	for ; n > 0; n-- {
+		q.buf[i] = nil
+		i = (i + 1) & m
 	}
+}
 
+func (q *SynchronizedRingQueue) full() bool {
+	return q.bounded && q.count == len(q.buf)
+}
+
+func (q *SynchronizedRingQueue) drop(e interface{}) {
+	if q.onDrop != nil {
+		q.onDrop(e)
+	}
+}
+
+// newRingBuffer returns a new ring buffer. Panics if the capacity is not
+// a power of 2.
+func newRingBuffer(capacity int) []interface{} {
+	checkPowerOfTwo(capacity)
+	return make([]interface{}, capacity)
+}
+
+// checkPowerOfTwo panics if the capacity n is not a power of 2 greater than 1.
+func checkPowerOfTwo(n int) {
+	if !isPowerOfTwo(n) {
+		panic("initial capacity must be power of 2")
+	}
+}
+
+// isPowerOfTwo returns true if n is a power of 2 greater than 1.
+func isPowerOfTwo(n int) bool {
+	return n >= 2 && n&(n-1) == 0
+}
+
+// resize moves the elements into a new buffer of the capacity n.
+func (q *SynchronizedRingQueue) resize(n int) {
+	buf := make([]interface{}, n)
+	q.copyTo(buf)
 	q.head = 0
-	q.tail = q.count
-	q.buf = newBuf
+	q.tail = q.count & (n - 1)
+	q.buf = buf
+}
+
+// copyTo copies the elements into dst reusing its underlying array if it has
+// enough capacity.
+func (q *SynchronizedRingQueue) copyTo(dst []interface{}) []interface{} {
+	if dst == nil || cap(dst) < q.count {
+		dst = make([]interface{}, q.count)
+	}
+	dst = dst[:q.count]
+	if q.count == 0 {
+		return dst
+	}
+
+	if q.tail > q.head {
+		copy(dst, q.buf[q.head:q.tail])
+	} else {
+		n := copy(dst, q.buf[q.head:])
+		copy(dst[n:], q.buf[:q.tail])
+	}
+	return dst
 }
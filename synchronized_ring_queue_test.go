@@ -1,7 +1,10 @@
 package concurrent
 
 import (
+	"context"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -86,3 +89,512 @@ func TestSynchronizedRingQueueRange(t *testing.T) {
 		}
 	}
 }
+
+func TestSynchronizedRingQueueRemoveIf(t *testing.T) {
+	const c = 8
+
+	for n := 0; n <= c; n++ {
+		for p := 0; p < c; p++ {
+			q := NewSynchronizedRingQueue(c)
+			// shift head to get wrap-around states
+			for i := 0; i < p; i++ {
+				q.Offer(-1)
+				q.Poll()
+			}
+			for i := 0; i < n; i++ {
+				q.Offer(i)
+			}
+
+			odd := func(e interface{}) bool { return e.(int)%2 != 0 }
+			assert.Equal(t, n/2, q.RemoveIf(odd))
+			assert.Equal(t, n-n/2, q.Size())
+			assert.Equal(t, c, q.Capacity())
+
+			small := func(e interface{}) bool { return e.(int) < 4 }
+			removed := q.RetainAll(small)
+			assert.Equal(t, n-n/2-removed, q.Size())
+
+			q.Offer(100)
+			for i := 0; i < n-n/2-removed; i++ {
+				assert.Equal(t, i*2, q.Poll())
+			}
+			assert.Equal(t, 100, q.Poll())
+			assert.Nil(t, q.Poll())
+		}
+	}
+}
+
+func TestSynchronizedRingQueueRemoveAll(t *testing.T) {
+	q := NewSynchronizedRingQueue(4)
+	q.OfferAll(1, 2, 3, 2, 4, 1)
+	eq := func(l, r interface{}) bool { return l == r }
+	assert.Equal(t, 4, q.RemoveAll(eq, 1, 2, 5))
+	assert.Equal(t, []interface{}{3, 4}, q.ToSlice())
+	assert.Equal(t, 0, q.RemoveAll(eq))
+}
+
+func TestSynchronizedRingQueueToSlice(t *testing.T) {
+	const c = 8
+
+	for n := 0; n <= c; n++ {
+		for p := 0; p < c; p++ {
+			q := NewSynchronizedRingQueue(c)
+			for i := 0; i < p; i++ {
+				q.Offer(-1)
+				q.Poll()
+			}
+			for i := 0; i < n; i++ {
+				q.Offer(i)
+			}
+
+			s := q.ToSlice()
+			assert.Equal(t, n, len(s))
+			for i := 0; i < n; i++ {
+				assert.Equal(t, i, s[i])
+			}
+
+			dst := make([]interface{}, 1, c)
+			r := q.ToSliceInto(dst)
+			assert.Equal(t, s, r)
+			assert.Equal(t, &dst[0], &r[:1][0], "Should reuse the destination")
+		}
+	}
+}
+
+func TestSynchronizedRingQueueVersion(t *testing.T) {
+	q := NewSynchronizedRingQueue(2)
+
+	v := q.Version()
+	for _, f := range []func(){
+		func() { q.Offer(0) },
+		func() { q.Offer(1) },
+		func() { q.Offer(2) },
+		func() { q.Poll() },
+		func() { q.RemoveIf(func(e interface{}) bool { return e == 1 }) },
+		func() { q.Clear() },
+	} {
+		f()
+		assert.True(t, q.Version() > v, "Version should move")
+		v = q.Version()
+	}
+
+	q.Poll()
+	q.Peek()
+	q.RemoveIf(func(interface{}) bool { return true })
+	assert.Equal(t, v, q.Version(), "Version should not move")
+}
+
+func TestSynchronizedRingQueueIterator(t *testing.T) {
+	const n = 10
+
+	q := NewSynchronizedRingQueue(2)
+	for i := 0; i < n; i++ {
+		q.Offer(i)
+	}
+
+	i := 0
+	it := q.Iterator(true)
+	for it.Next() {
+		assert.Equal(t, i, it.Value())
+		i++
+	}
+	assert.Nil(t, it.Err())
+	assert.Equal(t, n, i)
+
+	it = q.Iterator(true)
+	assert.True(t, it.Next())
+	q.Poll()
+	assert.False(t, it.Next())
+	assert.Equal(t, ErrConcurrentModification, it.Err())
+}
+
+func TestSynchronizedRingQueueTake(t *testing.T) {
+	const n = 1000
+	const consumers = 4
+
+	q := NewSynchronizedRingQueue(2)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	taken := make(map[int]bool, n)
+	for i := 0; i < consumers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				e, err := q.Take(context.Background())
+				if err != nil {
+					assert.Equal(t, ErrClosed, err)
+					return
+				}
+				mu.Lock()
+				taken[e.(int)] = true
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		assert.Nil(t, q.Put(context.Background(), i))
+	}
+	q.Close()
+	wg.Wait()
+
+	assert.Equal(t, n, len(taken))
+	assert.Equal(t, 0, q.Size())
+}
+
+func TestSynchronizedRingQueueTakeTimeout(t *testing.T) {
+	q := NewSynchronizedRingQueue(2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	e, err := q.Take(ctx)
+	assert.Nil(t, e)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	e, ok := q.PollTimeout(10 * time.Millisecond)
+	assert.Nil(t, e)
+	assert.False(t, ok)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.Offer(1)
+	}()
+	e, ok = q.PollTimeout(time.Second)
+	assert.True(t, ok)
+	assert.Equal(t, 1, e)
+
+	assert.True(t, q.OfferTimeout(2, time.Millisecond))
+	assert.Equal(t, 2, q.Poll())
+}
+
+func TestSynchronizedRingQueueClose(t *testing.T) {
+	q := NewSynchronizedRingQueue(2)
+	q.Offer(0)
+	q.Offer(1)
+	q.Close()
+
+	assert.False(t, q.TryOffer(2))
+	assert.Equal(t, ErrClosed, q.Put(context.Background(), 2))
+	assert.False(t, q.OfferTimeout(2, time.Millisecond))
+	assert.Equal(t, 2, q.Size())
+
+	e, err := q.Take(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, e)
+	assert.Equal(t, 1, q.Poll())
+
+	e, err = q.Take(context.Background())
+	assert.Nil(t, e)
+	assert.Equal(t, ErrClosed, err)
+}
+
+func TestBoundedSynchronizedRingQueueReject(t *testing.T) {
+	const c = 4
+
+	q := NewBoundedSynchronizedRingQueue(c, OverflowReject)
+	for i := 0; i < c; i++ {
+		assert.True(t, q.TryOffer(i))
+	}
+	assert.False(t, q.TryOffer(c))
+	assert.False(t, q.TryOffer(c))
+	assert.False(t, q.OfferTimeout(c, time.Millisecond))
+	assert.Equal(t, c, q.Size())
+	assert.Equal(t, c, q.Capacity())
+	assert.Equal(t, OverflowStats{Rejected: 2}, q.OverflowStats())
+
+	assert.Equal(t, 0, q.Poll())
+	assert.True(t, q.TryOffer(c))
+	assert.Equal(t, []interface{}{1, 2, 3, 4}, q.ToSlice())
+}
+
+func TestBoundedSynchronizedRingQueueDropOldest(t *testing.T) {
+	const c = 4
+
+	var dropped []interface{}
+	q := NewBoundedSynchronizedRingQueue(c, OverflowDropOldest)
+	q.SetDropHandler(func(e interface{}) { dropped = append(dropped, e) })
+
+	for i := 0; i < c*2+1; i++ {
+		assert.True(t, q.TryOffer(i))
+	}
+	assert.Equal(t, c, q.Capacity())
+	assert.Equal(t, []interface{}{5, 6, 7, 8}, q.ToSlice())
+	assert.Equal(t, []interface{}{0, 1, 2, 3, 4}, dropped)
+	assert.Equal(t, OverflowStats{DroppedOldest: 5}, q.OverflowStats())
+}
+
+func TestBoundedSynchronizedRingQueueDropNewest(t *testing.T) {
+	const c = 4
+
+	var dropped []interface{}
+	q := NewBoundedSynchronizedRingQueue(c, OverflowDropNewest)
+	q.SetDropHandler(func(e interface{}) { dropped = append(dropped, e) })
+
+	for i := 0; i < c; i++ {
+		assert.True(t, q.TryOffer(i))
+	}
+	for i := c; i < c*2; i++ {
+		assert.False(t, q.TryOffer(i), "Dropped element should not be reported as inserted")
+	}
+	assert.Equal(t, 0, q.OfferAll(c*2))
+	assert.Equal(t, c, q.Capacity())
+	assert.Equal(t, []interface{}{0, 1, 2, 3}, q.ToSlice())
+	assert.Equal(t, []interface{}{4, 5, 6, 7, 8}, dropped)
+	assert.Equal(t, OverflowStats{DroppedNewest: 5}, q.OverflowStats())
+}
+
+func TestBoundedSynchronizedRingQueueBlock(t *testing.T) {
+	const c = 4
+	const n = 1000
+
+	q := NewBoundedSynchronizedRingQueue(c, OverflowBlock)
+
+	var wg sync.WaitGroup
+	for p := 0; p < 4; p++ {
+		wg.Add(1)
+		go func(p int) {
+			for i := 0; i < n; i++ {
+				if i%2 == 0 {
+					assert.True(t, q.TryOffer(p*n+i))
+				} else {
+					assert.Nil(t, q.Put(context.Background(), p*n+i))
+				}
+				assert.True(t, q.Size() <= c)
+			}
+			wg.Done()
+		}(p)
+	}
+
+	taken := make(map[int]bool)
+	for len(taken) < 4*n {
+		e, err := q.Take(context.Background())
+		assert.Nil(t, err)
+		taken[e.(int)] = true
+	}
+	wg.Wait()
+	assert.Equal(t, OverflowStats{}, q.OverflowStats())
+
+	for i := 0; i < c; i++ {
+		q.Offer(i)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, q.Put(ctx, c))
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.Close()
+	}()
+	assert.False(t, q.TryOffer(c), "Blocked producer should be released on close")
+}
+
+func TestSynchronizedRingQueueShrink(t *testing.T) {
+	const c = 64
+
+	for p := 0; p < 16; p++ {
+		q := NewSynchronizedRingQueue(2)
+		q.SetShrinkPolicy(16, 8)
+
+		for i := 0; i < c; i++ {
+			q.Offer(i)
+		}
+		assert.Equal(t, c, q.Capacity())
+
+		// move the head so that the elements wrap around
+		next := c
+		for i := 0; i < c-16+p; i++ {
+			q.Poll()
+			q.Offer(next)
+			next++
+		}
+
+		expected := c - 16 + p
+		for q.Size() > 3 {
+			assert.Equal(t, expected, q.Poll())
+			expected++
+		}
+		// keep polling and offering with low occupancy
+		for i := 0; i < 100; i++ {
+			q.Offer(next)
+			next++
+			assert.Equal(t, expected, q.Poll())
+			expected++
+		}
+		assert.Equal(t, 16, q.Capacity(), "Should shrink down to the floor")
+		assert.Equal(t, 3, q.Size())
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, expected+i, q.Poll())
+		}
+		assert.Nil(t, q.Poll())
+	}
+}
+
+func TestSynchronizedRingQueueShrinkHysteresis(t *testing.T) {
+	q := NewSynchronizedRingQueue(2)
+	q.SetShrinkPolicy(2, 8)
+
+	for i := 0; i < 64; i++ {
+		q.Offer(i)
+	}
+	for q.Size() > 15 {
+		q.Poll()
+	}
+	// occupancy below 25% for less than 8 polls
+	for i := 0; i < 6; i++ {
+		q.Poll()
+	}
+	assert.Equal(t, 64, q.Capacity())
+	for i := 0; i < 10; i++ {
+		q.Offer(0)
+	}
+	q.Poll()
+	for i := 0; i < 7; i++ {
+		q.Poll()
+	}
+	assert.Equal(t, 64, q.Capacity(), "Occupancy has been back to 25%")
+
+	q.SetShrinkPolicy(2, 0)
+	for q.Size() > 0 {
+		q.Poll()
+	}
+	assert.Equal(t, 64, q.Capacity(), "Automatic shrinking is disabled")
+
+	assert.PanicsWithValue(t, "minCapacity must be power of 2", func() { q.SetShrinkPolicy(3, 0) })
+}
+
+func TestSynchronizedRingQueueTrimToSize(t *testing.T) {
+	for n := 0; n <= 16; n++ {
+		q := NewSynchronizedRingQueue(2)
+		q.SetShrinkPolicy(2, 0)
+		for i := 0; i < 32; i++ {
+			q.Offer(-1)
+		}
+		for i := 0; i < 32-n; i++ {
+			q.Poll()
+		}
+		for i := 0; i < n; i++ {
+			q.Poll()
+			q.Offer(i)
+		}
+		assert.Equal(t, 32, q.Capacity())
+
+		q.TrimToSize()
+		expected := 2
+		for expected < n {
+			expected <<= 1
+		}
+		assert.Equal(t, expected, q.Capacity())
+		assert.Equal(t, n, q.Size())
+
+		q.Offer(n)
+		for i := 0; i <= n; i++ {
+			assert.Equal(t, i, q.Poll())
+		}
+	}
+}
+
+func TestSynchronizedRingQueueClearShrinks(t *testing.T) {
+	q := NewSynchronizedRingQueue(4)
+	for i := 0; i < 100; i++ {
+		q.Offer(i)
+	}
+	assert.Equal(t, 128, q.Capacity())
+	q.Clear()
+	assert.Equal(t, 4, q.Capacity())
+	assert.Equal(t, 0, q.Size())
+	assert.Nil(t, q.Poll())
+}
+
+func TestSynchronizedRingQueueDrain(t *testing.T) {
+	const c = 8
+
+	for n := 0; n <= c; n++ {
+		for p := 0; p < c; p++ {
+			q := NewSynchronizedRingQueue(c)
+			for i := 0; i < p; i++ {
+				q.Offer(-1)
+				q.Poll()
+			}
+			for i := 0; i < n; i++ {
+				q.Offer(i)
+			}
+
+			dst := make([]interface{}, 3)
+			k := q.DrainTo(dst)
+			expected := 3
+			if n < 3 {
+				expected = n
+			}
+			assert.Equal(t, expected, k)
+			for i := 0; i < k; i++ {
+				assert.Equal(t, i, dst[i])
+			}
+
+			var drained []interface{}
+			assert.Equal(t, n-k, q.Drain(0, func(e interface{}) { drained = append(drained, e) }))
+			for i := range drained {
+				assert.Equal(t, k+i, drained[i])
+			}
+			assert.Equal(t, 0, q.Size())
+			assert.Equal(t, c, q.Capacity())
+
+			q.Offer(100)
+			assert.Equal(t, 100, q.Poll())
+		}
+	}
+}
+
+func TestSynchronizedRingQueueDrainMax(t *testing.T) {
+	q := NewSynchronizedRingQueue(4)
+	q.OfferAll(0, 1, 2, 3, 4)
+
+	var drained []interface{}
+	assert.Equal(t, 2, q.Drain(2, func(e interface{}) { drained = append(drained, e) }))
+	assert.Equal(t, []interface{}{0, 1}, drained)
+	assert.Equal(t, []interface{}{2, 3, 4}, q.ToSlice())
+	assert.Equal(t, 0, q.DrainTo(nil))
+}
+
+func TestSynchronizedRingQueueOfferAll(t *testing.T) {
+	const c = 8
+
+	for n := 0; n <= 2*c; n++ {
+		for p := 0; p < c; p++ {
+			q := NewSynchronizedRingQueue(c)
+			for i := 0; i < p; i++ {
+				q.Offer(-1)
+				q.Poll()
+			}
+			q.Offer(0)
+
+			es := make([]interface{}, n)
+			for i := range es {
+				es[i] = i + 1
+			}
+			assert.Equal(t, n, q.OfferAll(es...))
+			assert.Equal(t, n+1, q.Size())
+
+			q.Offer(n + 1)
+			for i := 0; i <= n+1; i++ {
+				assert.Equal(t, i, q.Poll())
+			}
+			assert.Nil(t, q.Poll())
+		}
+	}
+}
+
+func TestBoundedSynchronizedRingQueueOfferAll(t *testing.T) {
+	q := NewBoundedSynchronizedRingQueue(4, OverflowReject)
+	assert.Equal(t, 4, q.OfferAll(0, 1, 2, 3, 4, 5))
+	assert.Equal(t, OverflowStats{Rejected: 2}, q.OverflowStats())
+	assert.Equal(t, []interface{}{0, 1, 2, 3}, q.ToSlice())
+
+	q = NewBoundedSynchronizedRingQueue(4, OverflowDropOldest)
+	assert.Equal(t, 6, q.OfferAll(0, 1, 2, 3, 4, 5))
+	assert.Equal(t, []interface{}{2, 3, 4, 5}, q.ToSlice())
+
+	q.Close()
+	assert.Equal(t, 0, q.OfferAll(6))
+}
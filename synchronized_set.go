@@ -9,6 +9,7 @@ type SynchronizedSet struct {
 	sync.RWMutex
 	data     map[interface{}]bool
 	capacity int
+	version  uint64
 }
 
 // NewSynchronizedSet returns pointer to a new SynchronizedSet instance
@@ -31,13 +32,17 @@ func (s *SynchronizedSet) Size() int {
 func (s *SynchronizedSet) Clear() {
 	s.Lock()
 	s.data = make(map[interface{}]bool, s.capacity)
+	s.version++
 	s.Unlock()
 }
 
 // Add implements Set.Add
 func (s *SynchronizedSet) Add(v interface{}) {
 	s.Lock()
-	s.data[v] = true
+	if !s.data[v] {
+		s.data[v] = true
+		s.version++
+	}
 	s.Unlock()
 }
 
@@ -62,7 +67,99 @@ func (s *SynchronizedSet) Range(f func(e interface{}) bool) {
 
 // Remove implements Set.Remove
 func (s *SynchronizedSet) Remove(k interface{}) {
+	s.Lock()
+	if s.data[k] {
+		delete(s.data, k)
+		s.version++
+	}
+	s.Unlock()
+}
+
+// AddAll implements Set.AddAll
+func (s *SynchronizedSet) AddAll(es ...interface{}) int {
+	s.Lock()
+	defer s.Unlock()
+	n := len(s.data)
+	for _, e := range es {
+		s.data[e] = true
+	}
+	r := len(s.data) - n
+	if r > 0 {
+		s.version++
+	}
+	return r
+}
+
+// RemoveAll implements Set.RemoveAll
+func (s *SynchronizedSet) RemoveAll(es ...interface{}) int {
+	s.Lock()
+	defer s.Unlock()
+	n := len(s.data)
+	for _, e := range es {
+		delete(s.data, e)
+	}
+	r := n - len(s.data)
+	if r > 0 {
+		s.version++
+	}
+	return r
+}
+
+// RetainAll implements Set.RetainAll
+func (s *SynchronizedSet) RetainAll(f func(e interface{}) bool) int {
+	return s.RemoveIf(func(e interface{}) bool { return !f(e) })
+}
+
+// RemoveIf implements Set.RemoveIf
+func (s *SynchronizedSet) RemoveIf(f func(e interface{}) bool) int {
+	s.Lock()
+	defer s.Unlock()
+	n := len(s.data)
+	for k := range s.data {
+		if f(k) {
+			delete(s.data, k)
+		}
+	}
+	r := n - len(s.data)
+	if r > 0 {
+		s.version++
+	}
+	return r
+}
+
+// ToSlice implements Set.ToSlice
+func (s *SynchronizedSet) ToSlice() []interface{} {
+	return s.ToSliceInto(nil)
+}
+
+// ToSliceInto implements Set.ToSliceInto
+func (s *SynchronizedSet) ToSliceInto(dst []interface{}) []interface{} {
 	s.RLock()
-	delete(s.data, k)
-	s.RUnlock()
+	defer s.RUnlock()
+	if dst == nil {
+		dst = make([]interface{}, 0, len(s.data))
+	}
+	dst = dst[:0]
+	for k := range s.data {
+		dst = append(dst, k)
+	}
+	return dst
+}
+
+// Version implements Set.Version
+func (s *SynchronizedSet) Version() uint64 {
+	s.RLock()
+	defer s.RUnlock()
+	return s.version
+}
+
+// Iterator implements Set.Iterator
+func (s *SynchronizedSet) Iterator(failFast bool) Iterator {
+	s.RLock()
+	defer s.RUnlock()
+	data := make([]interface{}, 0, len(s.data))
+	for k := range s.data {
+		data = append(data, k)
+	}
+	return newSnapshotIterator(s, data, s.version, failFast)
 }
@@ -112,3 +112,111 @@ func TestSynchronizedSetRemove(t *testing.T) {
 
 	assert.Equal(t, 0, s.Size())
 }
+
+func TestSynchronizedSetBulk(t *testing.T) {
+	const n = 100
+
+	s := NewSynchronizedSet(0)
+
+	es := make([]interface{}, n)
+	for i := 0; i < n; i++ {
+		es[i] = i
+	}
+	assert.Equal(t, n, s.AddAll(es...))
+	assert.Equal(t, 0, s.AddAll(es...))
+	assert.Equal(t, n, s.Size())
+
+	assert.Equal(t, 3, s.RemoveAll(0, 1, 2, n))
+	assert.Equal(t, n-3, s.Size())
+
+	odd := func(e interface{}) bool { return e.(int)%2 != 0 }
+	assert.Equal(t, 49, s.RemoveIf(odd))
+	s.Range(func(e interface{}) bool {
+		assert.False(t, odd(e))
+		return true
+	})
+
+	small := func(e interface{}) bool { return e.(int) < n/2 }
+	assert.Equal(t, 25, s.RetainAll(small))
+	assert.Equal(t, 23, s.Size())
+	for i := 4; i < n/2; i += 2 {
+		assert.True(t, s.Contains(i))
+	}
+}
+
+func TestSynchronizedSetToSlice(t *testing.T) {
+	const n = 100
+
+	s := NewSynchronizedSet(0)
+	assert.Empty(t, s.ToSlice())
+
+	for i := 0; i < n; i++ {
+		s.Add(i)
+	}
+
+	var elms []int
+	for _, e := range s.ToSlice() {
+		elms = append(elms, e.(int))
+	}
+	sort.Ints(elms)
+	for i := 0; i < n; i++ {
+		assert.Equal(t, i, elms[i])
+	}
+
+	dst := make([]interface{}, 0, n)
+	r := s.ToSliceInto(dst)
+	assert.Equal(t, n, len(r))
+	assert.Equal(t, &dst[:1][0], &r[0], "Should reuse the destination")
+}
+
+func TestSynchronizedSetVersion(t *testing.T) {
+	s := NewSynchronizedSet(0)
+
+	v := s.Version()
+	for _, f := range []func(){
+		func() { s.Add(0) },
+		func() { s.AddAll(1, 2) },
+		func() { s.Remove(0) },
+		func() { s.RemoveAll(1) },
+		func() { s.RemoveIf(func(interface{}) bool { return true }) },
+		func() { s.Clear() },
+	} {
+		f()
+		assert.True(t, s.Version() > v, "Version should move")
+		v = s.Version()
+	}
+
+	s.Add(0)
+	v = s.Version()
+	s.Add(0)
+	s.Remove(1)
+	s.AddAll(0)
+	s.RemoveAll(1)
+	assert.Equal(t, v, s.Version(), "Version should not move")
+}
+
+func TestSynchronizedSetIterator(t *testing.T) {
+	const n = 10
+
+	s := NewSynchronizedSet(0)
+	for i := 0; i < n; i++ {
+		s.Add(i)
+	}
+
+	var elms []int
+	it := s.Iterator(true)
+	for it.Next() {
+		elms = append(elms, it.Value().(int))
+	}
+	assert.Nil(t, it.Err())
+	sort.Ints(elms)
+	for i := 0; i < n; i++ {
+		assert.Equal(t, i, elms[i])
+	}
+
+	it = s.Iterator(true)
+	assert.True(t, it.Next())
+	s.Remove(0)
+	assert.False(t, it.Next())
+	assert.Equal(t, ErrConcurrentModification, it.Err())
+}
@@ -0,0 +1,137 @@
+package concurrent
+
+import (
+	"runtime"
+	"sync"
+	"weak"
+)
+
+// SynchronizedWeakKeyMap is a safe for concurrent use map which holds its
+// keys weakly. An entry is removed automatically after its key has been
+// garbage collected. Keys are compared by identity.
+//
+// The values are held strongly, so a value must not reference its key,
+// otherwise the key is never collected.
+type SynchronizedWeakKeyMap[K, V any] struct {
+	sync.RWMutex
+	data     map[weak.Pointer[K]]weakKeyEntry[V]
+	capacity int
+}
+
+// weakKeyEntry is a map value together with the cleanup which removes the
+// entry once the key is collected.
+type weakKeyEntry[V any] struct {
+	v       V
+	cleanup runtime.Cleanup
+}
+
+// NewSynchronizedWeakKeyMap returns pointer to a new SynchronizedWeakKeyMap
+// instance.
+func NewSynchronizedWeakKeyMap[K, V any](capacity int) *SynchronizedWeakKeyMap[K, V] {
+	return &SynchronizedWeakKeyMap[K, V]{
+		data:     make(map[weak.Pointer[K]]weakKeyEntry[V], capacity),
+		capacity: capacity,
+	}
+}
+
+// Size returns the number of entries whose keys have not been removed yet.
+func (m *SynchronizedWeakKeyMap[K, V]) Size() int {
+	m.RLock()
+	r := len(m.data)
+	m.RUnlock()
+	return r
+}
+
+// Clear removes all the entries.
+func (m *SynchronizedWeakKeyMap[K, V]) Clear() {
+	m.Lock()
+	for _, e := range m.data {
+		e.cleanup.Stop()
+	}
+	m.data = make(map[weak.Pointer[K]]weakKeyEntry[V], m.capacity)
+	m.Unlock()
+}
+
+// Put puts a new key-value pair into the map. The key k must not be nil.
+// Returns the previous value associated with key, or the zero value if there
+// was no mapping for key.
+func (m *SynchronizedWeakKeyMap[K, V]) Put(k *K, v V) V {
+	p := weak.Make(k)
+
+	m.Lock()
+	defer m.Unlock()
+	o, ok := m.data[p]
+	if ok {
+		m.data[p] = weakKeyEntry[V]{v: v, cleanup: o.cleanup}
+		return o.v
+	}
+	c := runtime.AddCleanup(k, m.expunge, p)
+	m.data[p] = weakKeyEntry[V]{v: v, cleanup: c}
+	var zero V
+	return zero
+}
+
+// Get returns the value specified by the key and true if the key-value pair is
+// present, otherwise returns the zero value and false.
+func (m *SynchronizedWeakKeyMap[K, V]) Get(k *K) (V, bool) {
+	m.RLock()
+	e, ok := m.data[weak.Make(k)]
+	m.RUnlock()
+	return e.v, ok
+}
+
+// Contains returns true if the map contains the key k.
+func (m *SynchronizedWeakKeyMap[K, V]) Contains(k *K) bool {
+	_, ok := m.Get(k)
+	return ok
+}
+
+// Range calls f sequentially for each key and value present in the map.
+// Entries whose keys have been collected are skipped.
+// If f returns false, range stops the iteration.
+func (m *SynchronizedWeakKeyMap[K, V]) Range(f func(k *K, v V) bool) {
+	m.RLock()
+	defer m.RUnlock()
+	for p, e := range m.data {
+		k := p.Value()
+		if k == nil {
+			continue
+		}
+		if !f(k, e.v) {
+			return
+		}
+	}
+}
+
+// Remove removes the key-value pair specified by the key k from the map
+// if it is present.
+func (m *SynchronizedWeakKeyMap[K, V]) Remove(k *K) {
+	p := weak.Make(k)
+	m.Lock()
+	e, ok := m.data[p]
+	delete(m.data, p)
+	m.Unlock()
+	if ok {
+		e.cleanup.Stop()
+	}
+}
+
+// Keys returns the keys contained in the map which have not been collected.
+func (m *SynchronizedWeakKeyMap[K, V]) Keys() []*K {
+	m.RLock()
+	defer m.RUnlock()
+	r := make([]*K, 0, len(m.data))
+	for p := range m.data {
+		if k := p.Value(); k != nil {
+			r = append(r, k)
+		}
+	}
+	return r
+}
+
+// expunge removes the entry after its key has been collected.
+func (m *SynchronizedWeakKeyMap[K, V]) expunge(p weak.Pointer[K]) {
+	m.Lock()
+	delete(m.data, p)
+	m.Unlock()
+}
@@ -0,0 +1,79 @@
+package concurrent
+
+import (
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSynchronizedWeakKeyMapPutGet(t *testing.T) {
+	const n = 100
+
+	m := NewSynchronizedWeakKeyMap[weakTestObject, int](n)
+	assert.Equal(t, 0, m.Size(), "Empty map size must be equal to 0")
+
+	keys := make([]*weakTestObject, n)
+	for i := 0; i < n; i++ {
+		keys[i] = &weakTestObject{id: i, name: strconv.Itoa(i)}
+		assert.Equal(t, 0, m.Put(keys[i], i))
+	}
+	assert.Equal(t, n, m.Size())
+
+	for i := 0; i < n; i++ {
+		v, ok := m.Get(keys[i])
+		assert.True(t, ok)
+		assert.Equal(t, i, v)
+	}
+
+	// keys are compared by identity
+	assert.False(t, m.Contains(&weakTestObject{id: 0, name: "0"}))
+
+	assert.Equal(t, 0, m.Put(keys[0], n))
+	v, _ := m.Get(keys[0])
+	assert.Equal(t, n, v)
+
+	m.Remove(keys[1])
+	assert.False(t, m.Contains(keys[1]))
+	assert.Equal(t, n-1, m.Size())
+
+	m.Clear()
+	assert.Equal(t, 0, m.Size())
+	runtime.KeepAlive(keys)
+}
+
+func TestSynchronizedWeakKeyMapCollect(t *testing.T) {
+	const n = 100
+
+	m := NewSynchronizedWeakKeyMap[weakTestObject, int](n)
+
+	kept := make([]*weakTestObject, 0, n/2)
+	for i := 0; i < n; i++ {
+		k := &weakTestObject{id: i, name: strconv.Itoa(i)}
+		m.Put(k, i)
+		if i%2 == 0 {
+			kept = append(kept, k)
+		}
+	}
+
+	assert.Eventually(t, collected(func() bool { return m.Size() == n/2 }),
+		5*time.Second, 10*time.Millisecond, "Collected keys should be removed")
+
+	for _, k := range kept {
+		assert.True(t, m.Contains(k))
+	}
+	assert.Equal(t, n/2, len(m.Keys()))
+
+	m.Range(func(k *weakTestObject, v int) bool {
+		assert.Equal(t, k.id, v)
+		assert.Equal(t, 0, v%2)
+		return true
+	})
+
+	runtime.KeepAlive(kept)
+
+	assert.Eventually(t, collected(func() bool { return m.Size() == 0 }),
+		5*time.Second, 10*time.Millisecond, "Collected keys should be removed")
+}
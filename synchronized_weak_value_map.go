@@ -0,0 +1,143 @@
+package concurrent
+
+import (
+	"runtime"
+	"sync"
+	"weak"
+)
+
+// SynchronizedWeakValueMap is a safe for concurrent use map which holds its
+// values weakly. An entry is removed automatically after its value has been
+// garbage collected.
+type SynchronizedWeakValueMap[K comparable, V any] struct {
+	sync.RWMutex
+	data     map[K]weakValue[V]
+	capacity int
+}
+
+// weakValue is a weak reference to a map value together with the cleanup
+// which removes the entry once the value is collected.
+type weakValue[V any] struct {
+	p       weak.Pointer[V]
+	cleanup runtime.Cleanup
+}
+
+// weakValueRef identifies the entry to be removed by the cleanup.
+type weakValueRef[K comparable, V any] struct {
+	k K
+	p weak.Pointer[V]
+}
+
+// NewSynchronizedWeakValueMap returns pointer to a new
+// SynchronizedWeakValueMap instance.
+func NewSynchronizedWeakValueMap[K comparable, V any](capacity int) *SynchronizedWeakValueMap[K, V] {
+	return &SynchronizedWeakValueMap[K, V]{
+		data:     make(map[K]weakValue[V], capacity),
+		capacity: capacity,
+	}
+}
+
+// Size returns the number of entries whose values have not been removed yet.
+func (m *SynchronizedWeakValueMap[K, V]) Size() int {
+	m.RLock()
+	r := len(m.data)
+	m.RUnlock()
+	return r
+}
+
+// Clear removes all the entries.
+func (m *SynchronizedWeakValueMap[K, V]) Clear() {
+	m.Lock()
+	for _, e := range m.data {
+		e.cleanup.Stop()
+	}
+	m.data = make(map[K]weakValue[V], m.capacity)
+	m.Unlock()
+}
+
+// Put puts a new key-value pair into the map. The value v must not be nil.
+// Returns the previous value associated with key, or nil if there was no
+// mapping for key or its value has been collected.
+func (m *SynchronizedWeakValueMap[K, V]) Put(k K, v *V) *V {
+	p := weak.Make(v)
+	c := runtime.AddCleanup(v, m.expunge, weakValueRef[K, V]{k: k, p: p})
+
+	m.Lock()
+	o, ok := m.data[k]
+	m.data[k] = weakValue[V]{p: p, cleanup: c}
+	m.Unlock()
+
+	if !ok {
+		return nil
+	}
+	o.cleanup.Stop()
+	return o.p.Value()
+}
+
+// Get returns the value specified by the key if the key-value pair is present
+// and the value has not been collected, otherwise returns nil.
+func (m *SynchronizedWeakValueMap[K, V]) Get(k K) *V {
+	m.RLock()
+	e, ok := m.data[k]
+	m.RUnlock()
+	if !ok {
+		return nil
+	}
+	return e.p.Value()
+}
+
+// Contains returns true if the map contains the key k and its value has not
+// been collected.
+func (m *SynchronizedWeakValueMap[K, V]) Contains(k K) bool {
+	return m.Get(k) != nil
+}
+
+// Range calls f sequentially for each key and value present in the map.
+// Entries whose values have been collected are skipped.
+// If f returns false, range stops the iteration.
+func (m *SynchronizedWeakValueMap[K, V]) Range(f func(k K, v *V) bool) {
+	m.RLock()
+	defer m.RUnlock()
+	for k, e := range m.data {
+		v := e.p.Value()
+		if v == nil {
+			continue
+		}
+		if !f(k, v) {
+			return
+		}
+	}
+}
+
+// Remove removes the key-value pair specified by the key k from the map
+// if it is present.
+func (m *SynchronizedWeakValueMap[K, V]) Remove(k K) {
+	m.Lock()
+	e, ok := m.data[k]
+	delete(m.data, k)
+	m.Unlock()
+	if ok {
+		e.cleanup.Stop()
+	}
+}
+
+// Keys returns the keys contained in the map.
+func (m *SynchronizedWeakValueMap[K, V]) Keys() []K {
+	m.RLock()
+	defer m.RUnlock()
+	r := make([]K, 0, len(m.data))
+	for k := range m.data {
+		r = append(r, k)
+	}
+	return r
+}
+
+// expunge removes the entry after its value has been collected unless the
+// entry has been replaced in the meantime.
+func (m *SynchronizedWeakValueMap[K, V]) expunge(r weakValueRef[K, V]) {
+	m.Lock()
+	if e, ok := m.data[r.k]; ok && e.p == r.p {
+		delete(m.data, r.k)
+	}
+	m.Unlock()
+}
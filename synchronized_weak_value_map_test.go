@@ -0,0 +1,106 @@
+package concurrent
+
+import (
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type weakTestObject struct {
+	id   int
+	name string
+}
+
+func collected(f func() bool) func() bool {
+	return func() bool {
+		runtime.GC()
+		return f()
+	}
+}
+
+func TestSynchronizedWeakValueMapPutGet(t *testing.T) {
+	const n = 100
+
+	m := NewSynchronizedWeakValueMap[int, weakTestObject](n)
+	assert.Equal(t, 0, m.Size(), "Empty map size must be equal to 0")
+
+	values := make([]*weakTestObject, n)
+	for i := 0; i < n; i++ {
+		values[i] = &weakTestObject{id: i, name: strconv.Itoa(i)}
+		assert.Nil(t, m.Put(i, values[i]))
+	}
+	assert.Equal(t, n, m.Size())
+
+	for i := 0; i < n; i++ {
+		assert.True(t, m.Contains(i))
+		assert.Equal(t, values[i], m.Get(i))
+	}
+
+	o := &weakTestObject{id: n}
+	assert.Equal(t, values[0], m.Put(0, o))
+	assert.Equal(t, o, m.Get(0))
+
+	m.Remove(1)
+	assert.False(t, m.Contains(1))
+	assert.Nil(t, m.Get(1))
+	assert.Equal(t, n-1, m.Size())
+
+	m.Clear()
+	assert.Equal(t, 0, m.Size())
+	runtime.KeepAlive(values)
+}
+
+func TestSynchronizedWeakValueMapCollect(t *testing.T) {
+	const n = 100
+
+	m := NewSynchronizedWeakValueMap[int, weakTestObject](n)
+
+	kept := make([]*weakTestObject, 0, n/2)
+	for i := 0; i < n; i++ {
+		v := &weakTestObject{id: i, name: strconv.Itoa(i)}
+		m.Put(i, v)
+		if i%2 == 0 {
+			kept = append(kept, v)
+		}
+	}
+
+	assert.Eventually(t, collected(func() bool { return m.Size() == n/2 }),
+		5*time.Second, 10*time.Millisecond, "Collected values should be removed")
+
+	for i := 0; i < n; i++ {
+		assert.Equal(t, i%2 == 0, m.Contains(i))
+	}
+	assert.Equal(t, n/2, len(m.Keys()))
+
+	cnt := 0
+	m.Range(func(k int, v *weakTestObject) bool {
+		assert.Equal(t, k, v.id)
+		cnt++
+		return true
+	})
+	assert.Equal(t, n/2, cnt)
+
+	runtime.KeepAlive(kept)
+
+	assert.Eventually(t, collected(func() bool { return m.Size() == 0 }),
+		5*time.Second, 10*time.Millisecond, "Collected values should be removed")
+}
+
+func TestSynchronizedWeakValueMapReplaced(t *testing.T) {
+	m := NewSynchronizedWeakValueMap[string, weakTestObject](0)
+
+	m.Put("k", &weakTestObject{id: 1})
+	v := &weakTestObject{id: 2}
+	m.Put("k", v)
+
+	for i := 0; i < 3; i++ {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 1, m.Size(), "Replaced entry should not be removed")
+	assert.Equal(t, v, m.Get("k"))
+	runtime.KeepAlive(v)
+}
@@ -0,0 +1,89 @@
+package concurrent
+
+import (
+	"context"
+	"time"
+)
+
+// SynchronousQueue is a safe for concurrent use BlockingQueue in which each
+// insertion waits for a consumer to take the element. The queue has no
+// capacity: TryOffer succeeds only if a consumer is waiting, and Size, Peek,
+// Range and ToSlice always see the queue empty.
+//
+// If the queue is fair, the waiting producers and consumers are matched in
+// FIFO order, otherwise the most recent waiter is matched first.
+type SynchronousQueue struct {
+	handoffQueue
+}
+
+// NewSynchronousQueue returns pointer to a new SynchronousQueue instance.
+func NewSynchronousQueue(fair bool) *SynchronousQueue {
+	return &SynchronousQueue{handoffQueue{fair: fair}}
+}
+
+// Size implements Queue.Size. Always returns 0.
+func (q *SynchronousQueue) Size() int {
+	return 0
+}
+
+// Clear implements Queue.Clear. Does nothing.
+func (q *SynchronousQueue) Clear() {
+}
+
+// Offer implements Queue.Offer
+func (q *SynchronousQueue) Offer(e interface{}) {
+	q.TryOffer(e)
+}
+
+// TryOffer implements Queue.TryOffer. Returns false if there is no consumer
+// waiting for the element.
+func (q *SynchronousQueue) TryOffer(e interface{}) bool {
+	return q.tryTransfer(e)
+}
+
+// Peek implements Queue.Peek. Always returns nil.
+func (q *SynchronousQueue) Peek() interface{} {
+	return nil
+}
+
+// Range implements Queue.Range. Does nothing.
+func (q *SynchronousQueue) Range(f func(e interface{}) bool) {
+}
+
+// ToSlice implements Queue.ToSlice. Always returns an empty slice.
+func (q *SynchronousQueue) ToSlice() []interface{} {
+	return []interface{}{}
+}
+
+// ToSliceInto implements Queue.ToSliceInto. Always returns an empty slice.
+func (q *SynchronousQueue) ToSliceInto(dst []interface{}) []interface{} {
+	if dst == nil {
+		return []interface{}{}
+	}
+	return dst[:0]
+}
+
+// OfferAll implements Queue.OfferAll. Each element is handed off to a waiting
+// consumer if there is one.
+func (q *SynchronousQueue) OfferAll(es ...interface{}) int {
+	n := 0
+	for _, e := range es {
+		if q.tryTransfer(e) {
+			n++
+		}
+	}
+	return n
+}
+
+// Put implements BlockingQueue.Put. Waits until a consumer takes the element.
+func (q *SynchronousQueue) Put(ctx context.Context, e interface{}) error {
+	return q.transfer(ctx, e, true)
+}
+
+// OfferTimeout implements BlockingQueue.OfferTimeout. Waits up to the
+// duration d for a consumer to take the element.
+func (q *SynchronousQueue) OfferTimeout(e interface{}, d time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return q.Put(ctx, e) == nil
+}
@@ -0,0 +1,137 @@
+package concurrent
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func waitingProducers(q *handoffQueue) func() int {
+	return func() int {
+		q.RLock()
+		defer q.RUnlock()
+		return len(q.producers)
+	}
+}
+
+func TestSynchronousQueueHandoff(t *testing.T) {
+	q := NewSynchronousQueue(false)
+	var _ BlockingQueue = q
+
+	assert.False(t, q.TryOffer(1), "Should not accept elements without consumers")
+	assert.Nil(t, q.Poll())
+	assert.Equal(t, 0, q.OfferAll(1, 2))
+
+	taken := make(chan interface{})
+	go func() {
+		e, err := q.Take(context.Background())
+		assert.Nil(t, err)
+		taken <- e
+	}()
+	assert.Eventually(t, func() bool { return q.WaitingConsumers() == 1 }, time.Second, time.Millisecond)
+	assert.True(t, q.TryOffer(1))
+	assert.Equal(t, 1, <-taken)
+
+	put := make(chan error)
+	go func() { put <- q.Put(context.Background(), 2) }()
+	assert.Eventually(t, func() bool { return waitingProducers(&q.handoffQueue)() == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, 0, q.Size())
+	assert.Nil(t, q.Peek())
+	assert.Empty(t, q.ToSlice())
+
+	select {
+	case <-put:
+		t.Fatal("Put should wait for a consumer")
+	default:
+	}
+	assert.Equal(t, 2, q.Poll())
+	assert.Nil(t, <-put)
+}
+
+func TestSynchronousQueueTimeouts(t *testing.T) {
+	q := NewSynchronousQueue(true)
+
+	assert.False(t, q.OfferTimeout(1, 10*time.Millisecond))
+	assert.Equal(t, 0, waitingProducers(&q.handoffQueue)(), "Timed out producer should be removed")
+
+	_, ok := q.PollTimeout(10 * time.Millisecond)
+	assert.False(t, ok)
+	assert.Equal(t, 0, q.WaitingConsumers(), "Timed out consumer should be removed")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, q.Put(ctx, 1))
+}
+
+func TestSynchronousQueueFairness(t *testing.T) {
+	for _, fair := range []bool{true, false} {
+		q := NewSynchronousQueue(fair)
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				q.Put(context.Background(), i)
+			}(i)
+			assert.Eventually(t, func() bool { return waitingProducers(&q.handoffQueue)() == i+1 }, time.Second, time.Millisecond)
+		}
+
+		dst := make([]interface{}, 3)
+		assert.Equal(t, 3, q.DrainTo(dst))
+		if fair {
+			assert.Equal(t, []interface{}{0, 1, 2}, dst)
+		} else {
+			assert.Equal(t, []interface{}{2, 1, 0}, dst)
+		}
+		wg.Wait()
+	}
+}
+
+func TestSynchronousQueueClose(t *testing.T) {
+	q := NewSynchronousQueue(true)
+
+	res := make(chan error)
+	go func() {
+		_, err := q.Take(context.Background())
+		res <- err
+	}()
+	assert.Eventually(t, func() bool { return q.WaitingConsumers() == 1 }, time.Second, time.Millisecond)
+	q.Close()
+	assert.Equal(t, ErrClosed, <-res)
+	assert.Equal(t, ErrClosed, q.Put(context.Background(), 1))
+	_, err := q.Take(context.Background())
+	assert.Equal(t, ErrClosed, err)
+}
+
+func TestSynchronousQueueConcurrent(t *testing.T) {
+	const producers = 4
+	const n = 500
+
+	q := NewSynchronousQueue(false)
+	var wg sync.WaitGroup
+	for i := 0; i < producers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 1; j <= n; j++ {
+				if err := q.Put(context.Background(), j); err != nil {
+					panic(err)
+				}
+			}
+		}()
+	}
+
+	sum := 0
+	for i := 0; i < producers*n; i++ {
+		e, err := q.Take(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum += e.(int)
+	}
+	wg.Wait()
+	assert.Equal(t, producers*n*(n+1)/2, sum)
+}
@@ -0,0 +1,134 @@
+package concurrent
+
+import (
+	"context"
+	"time"
+)
+
+// TransferQueue is a safe for concurrent use unbounded BlockingQueue in which
+// producers may wait for their elements to be consumed. Offer and Put insert
+// the element and return immediately, while Transfer waits until a consumer
+// takes it.
+//
+// The offered elements are always retrieved in FIFO order. If the queue is
+// fair, the producers waiting in Transfer and the waiting consumers are
+// served in FIFO order too, otherwise the most recent waiters go first.
+type TransferQueue struct {
+	handoffQueue
+}
+
+// NewTransferQueue returns pointer to a new TransferQueue instance.
+func NewTransferQueue(fair bool) *TransferQueue {
+	return &TransferQueue{handoffQueue{fair: fair}}
+}
+
+// Size implements Queue.Size. The elements being transferred are counted.
+func (q *TransferQueue) Size() int {
+	q.RLock()
+	defer q.RUnlock()
+	return len(q.producers)
+}
+
+// Clear implements Queue.Clear. Only the offered elements are removed, the
+// elements being transferred stay in the queue.
+func (q *TransferQueue) Clear() {
+	q.Lock()
+	defer q.Unlock()
+	ps := q.producers[:0]
+	for _, p := range q.producers {
+		if p.done != nil {
+			ps = append(ps, p)
+		}
+	}
+	for i := len(ps); i < len(q.producers); i++ {
+		q.producers[i] = nil
+	}
+	q.producers = ps
+}
+
+// Offer implements Queue.Offer
+func (q *TransferQueue) Offer(e interface{}) {
+	q.TryOffer(e)
+}
+
+// TryOffer implements Queue.TryOffer. Returns false if the queue is closed.
+func (q *TransferQueue) TryOffer(e interface{}) bool {
+	return q.transfer(context.Background(), e, false) == nil
+}
+
+// Transfer inserts the element e and waits until a consumer takes it or ctx
+// is done. Returns ErrClosed if the queue is closed, or the context error if
+// ctx is done before the element is taken; in that case the element is
+// removed from the queue.
+func (q *TransferQueue) Transfer(ctx context.Context, e interface{}) error {
+	return q.transfer(ctx, e, true)
+}
+
+// TryTransfer hands the element e off to a waiting consumer. Returns false
+// and does not insert the element if there is no consumer waiting.
+func (q *TransferQueue) TryTransfer(e interface{}) bool {
+	return q.tryTransfer(e)
+}
+
+// Peek implements Queue.Peek
+func (q *TransferQueue) Peek() interface{} {
+	q.RLock()
+	defer q.RUnlock()
+	if len(q.producers) == 0 {
+		return nil
+	}
+	return q.producers[q.nextProducer()].e
+}
+
+// Range implements Queue.Range. The elements are visited in insertion order.
+func (q *TransferQueue) Range(f func(e interface{}) bool) {
+	q.RLock()
+	defer q.RUnlock()
+	for _, p := range q.producers {
+		if !f(p.e) {
+			return
+		}
+	}
+}
+
+// ToSlice implements Queue.ToSlice. The elements are returned in insertion
+// order.
+func (q *TransferQueue) ToSlice() []interface{} {
+	return q.ToSliceInto(nil)
+}
+
+// ToSliceInto implements Queue.ToSliceInto. The elements are copied in
+// insertion order.
+func (q *TransferQueue) ToSliceInto(dst []interface{}) []interface{} {
+	q.RLock()
+	defer q.RUnlock()
+	if dst == nil {
+		dst = make([]interface{}, 0, len(q.producers))
+	}
+	dst = dst[:0]
+	for _, p := range q.producers {
+		dst = append(dst, p.e)
+	}
+	return dst
+}
+
+// OfferAll implements Queue.OfferAll
+func (q *TransferQueue) OfferAll(es ...interface{}) int {
+	for i, e := range es {
+		if !q.TryOffer(e) {
+			return i
+		}
+	}
+	return len(es)
+}
+
+// Put implements BlockingQueue.Put. The queue is unbounded, so Put never
+// waits.
+func (q *TransferQueue) Put(ctx context.Context, e interface{}) error {
+	return q.transfer(ctx, e, false)
+}
+
+// OfferTimeout implements BlockingQueue.OfferTimeout
+func (q *TransferQueue) OfferTimeout(e interface{}, d time.Duration) bool {
+	return q.TryOffer(e)
+}
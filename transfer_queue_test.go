@@ -0,0 +1,145 @@
+package concurrent
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransferQueueOffer(t *testing.T) {
+	q := NewTransferQueue(true)
+	var _ BlockingQueue = q
+
+	assert.True(t, q.TryOffer(1))
+	assert.Nil(t, q.Put(context.Background(), 2))
+	assert.Equal(t, 2, q.OfferAll(3, 4))
+	assert.Equal(t, 4, q.Size())
+	assert.Equal(t, 1, q.Peek())
+	assert.Equal(t, []interface{}{1, 2, 3, 4}, q.ToSlice())
+
+	assert.Equal(t, 1, q.Poll())
+	var got []interface{}
+	assert.Equal(t, 3, q.Drain(0, func(e interface{}) { got = append(got, e) }))
+	assert.Equal(t, []interface{}{2, 3, 4}, got)
+	assert.Nil(t, q.Poll())
+
+	u := NewTransferQueue(false)
+	u.OfferAll(1, 2, 3)
+	assert.Equal(t, 1, u.Peek(), "Offered elements should be FIFO in unfair queue")
+	assert.Equal(t, 1, u.Poll())
+	got = got[:0]
+	u.Drain(0, func(e interface{}) { got = append(got, e) })
+	assert.Equal(t, []interface{}{2, 3}, got)
+}
+
+func TestTransferQueueUnfairTransfer(t *testing.T) {
+	q := NewTransferQueue(false)
+
+	res := make(chan error, 2)
+	go func() { res <- q.Transfer(context.Background(), 1) }()
+	assert.Eventually(t, func() bool { return q.Size() == 1 }, time.Second, time.Millisecond)
+	go func() { res <- q.Transfer(context.Background(), 2) }()
+	assert.Eventually(t, func() bool { return q.Size() == 2 }, time.Second, time.Millisecond)
+	q.Offer(3)
+
+	// the most recent waiting producer goes first, the offered element keeps
+	// its place after the waiting producers
+	assert.Equal(t, 2, q.Peek())
+	assert.Equal(t, 2, q.Poll())
+	assert.Nil(t, <-res)
+	assert.Equal(t, 1, q.Poll())
+	assert.Nil(t, <-res)
+	assert.Equal(t, 3, q.Poll())
+}
+
+func TestTransferQueueTransfer(t *testing.T) {
+	q := NewTransferQueue(true)
+	q.Offer(1)
+
+	res := make(chan error)
+	go func() { res <- q.Transfer(context.Background(), 2) }()
+	assert.Eventually(t, func() bool { return q.Size() == 2 }, time.Second, time.Millisecond)
+
+	q.Clear()
+	assert.Equal(t, []interface{}{2}, q.ToSlice(), "Clear should keep transferred elements")
+
+	select {
+	case <-res:
+		t.Fatal("Transfer should wait for a consumer")
+	default:
+	}
+	e, err := q.Take(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 2, e)
+	assert.Nil(t, <-res)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, q.Transfer(ctx, 3))
+	assert.Equal(t, 0, q.Size(), "Cancelled transfer should remove the element")
+}
+
+func TestTransferQueueTryTransfer(t *testing.T) {
+	q := NewTransferQueue(true)
+	assert.False(t, q.TryTransfer(1))
+	assert.Equal(t, 0, q.Size())
+
+	taken := make(chan interface{})
+	go func() {
+		e, _ := q.Take(context.Background())
+		taken <- e
+	}()
+	assert.Eventually(t, func() bool { return q.WaitingConsumers() == 1 }, time.Second, time.Millisecond)
+	assert.True(t, q.TryTransfer(1))
+	assert.Equal(t, 1, <-taken)
+}
+
+func TestTransferQueueClose(t *testing.T) {
+	q := NewTransferQueue(true)
+	q.Offer(1)
+	q.Close()
+	assert.False(t, q.TryOffer(2))
+	assert.Equal(t, 0, q.OfferAll(2))
+	assert.Equal(t, ErrClosed, q.Transfer(context.Background(), 2))
+
+	e, ok := q.PollTimeout(time.Millisecond)
+	assert.True(t, ok)
+	assert.Equal(t, 1, e)
+	_, err := q.Take(context.Background())
+	assert.Equal(t, ErrClosed, err)
+}
+
+func TestTransferQueueConcurrent(t *testing.T) {
+	const producers = 4
+	const n = 500
+
+	q := NewTransferQueue(true)
+	var wg sync.WaitGroup
+	for i := 0; i < producers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 1; j <= n; j++ {
+				if i%2 == 0 {
+					q.Offer(j)
+				} else if err := q.Transfer(context.Background(), j); err != nil {
+					panic(err)
+				}
+			}
+		}(i)
+	}
+
+	sum := 0
+	for i := 0; i < producers*n; i++ {
+		e, err := q.Take(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum += e.(int)
+	}
+	wg.Wait()
+	assert.Equal(t, producers*n*(n+1)/2, sum)
+}
@@ -0,0 +1,99 @@
+package concurrent
+
+import (
+	"context"
+	"sync"
+)
+
+// WaitStrategy defines how an event processor waits for a sequence to become
+// available.
+type WaitStrategy interface {
+	// WaitFor waits until the sequence seq is published to cursor and
+	// processed by all the dependents. Returns the highest available
+	// sequence, which may be greater than seq, or ErrAlerted if the barrier b
+	// has been alerted.
+	WaitFor(seq int64, cursor *Sequence, dependents []*Sequence, b *SequenceBarrier) (int64, error)
+
+	// SignalAll notifies the waiting processors that the cursor has moved.
+	SignalAll()
+}
+
+// idleWaitStrategy polls the sequences and idles in between.
+type idleWaitStrategy struct {
+	idle IdleStrategy
+}
+
+// blockingWaitStrategy waits on a condition variable for the cursor to move.
+type blockingWaitStrategy struct {
+	sync.Mutex
+	moved cond
+	idle  IdleStrategy
+}
+
+// NewIdleWaitStrategy returns a new WaitStrategy which calls the idle strategy
+// while the sequence is not available. If idle is nil, the yielding strategy
+// is used.
+func NewIdleWaitStrategy(idle IdleStrategy) WaitStrategy {
+	if idle == nil {
+		idle = NewYeildingIdleStrategy()
+	}
+	return &idleWaitStrategy{idle: idle}
+}
+
+// NewBlockingWaitStrategy returns a new WaitStrategy which blocks the
+// processor until the cursor moves. It calls the idle strategy while waiting
+// for the dependent processors. If idle is nil, the yielding strategy is used.
+func NewBlockingWaitStrategy(idle IdleStrategy) WaitStrategy {
+	if idle == nil {
+		idle = NewYeildingIdleStrategy()
+	}
+	return &blockingWaitStrategy{idle: idle}
+}
+
+// WaitFor implements WaitStrategy.WaitFor
+func (s *idleWaitStrategy) WaitFor(seq int64, cursor *Sequence, dependents []*Sequence, b *SequenceBarrier) (int64, error) {
+	for {
+		if avail := minSequence(dependents, cursor.Get()); avail >= seq {
+			return avail, nil
+		}
+		if b.IsAlerted() {
+			return InitialSequence, ErrAlerted
+		}
+		s.idle.Idle()
+	}
+}
+
+// SignalAll implements WaitStrategy.SignalAll
+func (s *idleWaitStrategy) SignalAll() {}
+
+// WaitFor implements WaitStrategy.WaitFor
+func (s *blockingWaitStrategy) WaitFor(seq int64, cursor *Sequence, dependents []*Sequence, b *SequenceBarrier) (int64, error) {
+	if cursor.Get() < seq {
+		s.Lock()
+		for cursor.Get() < seq {
+			if b.IsAlerted() {
+				s.Unlock()
+				return InitialSequence, ErrAlerted
+			}
+			s.moved.wait(context.Background(), s)
+		}
+		s.Unlock()
+	}
+
+	for {
+		if avail := minSequence(dependents, cursor.Get()); avail >= seq {
+			return avail, nil
+		}
+		if b.IsAlerted() {
+			return InitialSequence, ErrAlerted
+		}
+		s.idle.Idle()
+	}
+}
+
+// SignalAll implements WaitStrategy.SignalAll
+func (s *blockingWaitStrategy) SignalAll() {
+	s.Lock()
+	s.moved.broadcast()
+	s.Unlock()
+}
@@ -0,0 +1,137 @@
+package concurrent
+
+import (
+	"sync/atomic"
+)
+
+// WorkStealingDeque is a lock-free growable deque proposed by Chase and Lev.
+// The owner pushes and pops the elements at the bottom, while any number of
+// thieves steal them from the top. Push and Pop must be called only by the
+// owner goroutine; Steal and Size are safe for concurrent use.
+//
+// The elements must not be nil.
+type WorkStealingDeque struct {
+	_      cacheLinePad
+	top    atomic.Int64
+	_      cacheLinePad
+	bottom atomic.Int64
+	_      cacheLinePad
+	array  atomic.Pointer[wsArray]
+}
+
+// wsArray is a circular array of the deque elements. It is replaced, never
+// modified in size, so thieves may keep reading the old one.
+type wsArray struct {
+	buf  []atomic.Pointer[interface{}]
+	mask int64
+}
+
+// NewWorkStealingDeque returns pointer to a new WorkStealingDeque instance.
+// The initial capacity must be power of 2; the deque doubles it when full.
+func NewWorkStealingDeque(initialCapacity int) *WorkStealingDeque {
+	checkPowerOfTwo(initialCapacity)
+	d := &WorkStealingDeque{}
+	d.array.Store(newWSArray(initialCapacity))
+	return d
+}
+
+// Size returns the number of the elements in the deque. The result is
+// weakly consistent.
+func (d *WorkStealingDeque) Size() int {
+	n := d.bottom.Load() - d.top.Load()
+	if n < 0 {
+		return 0
+	}
+	return int(n)
+}
+
+// Push inserts the element e at the bottom of the deque. Must be called only
+// by the owner.
+func (d *WorkStealingDeque) Push(e interface{}) {
+	b := d.bottom.Load()
+	t := d.top.Load()
+	a := d.array.Load()
+	if b-t >= int64(len(a.buf))-1 {
+		a = a.grow(t, b)
+		d.array.Store(a)
+	}
+	a.put(b, e)
+	d.bottom.Store(b + 1)
+}
+
+// Pop retrieves and removes the bottom element of the deque; returns nil if
+// the deque is empty. Must be called only by the owner.
+func (d *WorkStealingDeque) Pop() interface{} {
+	b := d.bottom.Load() - 1
+	a := d.array.Load()
+	d.bottom.Store(b)
+	t := d.top.Load()
+
+	if t > b {
+		d.bottom.Store(b + 1)
+		return nil
+	}
+
+	e := a.get(b)
+	if t < b {
+		a.clear(b)
+		return e
+	}
+
+	// the last element, race against the thieves
+	if d.top.CompareAndSwap(t, t+1) {
+		a.clear(b)
+	} else {
+		e = nil
+	}
+	d.bottom.Store(b + 1)
+	return e
+}
+
+// Steal retrieves and removes the top element of the deque; returns nil if
+// the deque is empty or the element has been taken by another goroutine
+// concurrently.
+func (d *WorkStealingDeque) Steal() interface{} {
+	t := d.top.Load()
+	b := d.bottom.Load()
+	if t >= b {
+		return nil
+	}
+	e := d.array.Load().get(t)
+	if !d.top.CompareAndSwap(t, t+1) {
+		return nil
+	}
+	return e
+}
+
+func newWSArray(n int) *wsArray {
+	return &wsArray{
+		buf:  make([]atomic.Pointer[interface{}], n),
+		mask: int64(n - 1),
+	}
+}
+
+func (a *wsArray) get(i int64) interface{} {
+	if p := a.buf[i&a.mask].Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+func (a *wsArray) put(i int64, e interface{}) {
+	a.buf[i&a.mask].Store(&e)
+}
+
+func (a *wsArray) clear(i int64) {
+	a.buf[i&a.mask].Store(nil)
+}
+
+// grow returns a copy of the array of the double size containing the
+// elements from t to b.
+func (a *wsArray) grow(t, b int64) *wsArray {
+	r := newWSArray(len(a.buf) << 1)
+	for i := t; i < b; i++ {
+		r.buf[i&r.mask].Store(a.buf[i&a.mask].Load())
+	}
+	return r
+}
@@ -0,0 +1,89 @@
+package concurrent
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkStealingDeque(t *testing.T) {
+	d := NewWorkStealingDeque(2)
+	assert.Nil(t, d.Pop())
+	assert.Nil(t, d.Steal())
+
+	for i := 0; i < 10; i++ {
+		d.Push(i)
+	}
+	assert.Equal(t, 10, d.Size())
+
+	assert.Equal(t, 9, d.Pop(), "Owner should pop LIFO")
+	assert.Equal(t, 0, d.Steal(), "Thieves should steal FIFO")
+	assert.Equal(t, 1, d.Steal())
+	assert.Equal(t, 8, d.Pop())
+	assert.Equal(t, 6, d.Size())
+
+	for i := 2; i < 8; i++ {
+		assert.Equal(t, i, d.Steal())
+	}
+	assert.Nil(t, d.Steal())
+	assert.Nil(t, d.Pop())
+	assert.Equal(t, 0, d.Size())
+
+	d.Push(42)
+	assert.Equal(t, 42, d.Pop())
+	assert.Nil(t, d.Pop())
+
+	assert.Panics(t, func() { NewWorkStealingDeque(3) })
+}
+
+func TestWorkStealingDequeConcurrent(t *testing.T) {
+	const thieves = 3
+	const n = 20000
+
+	d := NewWorkStealingDeque(4)
+	taken := make([]int32, n)
+	var total atomic.Int64
+	var done atomic.Bool
+
+	var wg sync.WaitGroup
+	for i := 0; i < thieves; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !done.Load() || d.Size() > 0 {
+				if e := d.Steal(); e != nil {
+					atomic.AddInt32(&taken[e.(int)], 1)
+					total.Add(1)
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		d.Push(i)
+		if i%3 == 0 {
+			if e := d.Pop(); e != nil {
+				atomic.AddInt32(&taken[e.(int)], 1)
+				total.Add(1)
+			}
+		}
+	}
+	for e := d.Pop(); e != nil; e = d.Pop() {
+		atomic.AddInt32(&taken[e.(int)], 1)
+		total.Add(1)
+	}
+	done.Store(true)
+	wg.Wait()
+
+	assert.Equal(t, int64(n), total.Load())
+	for i, c := range taken {
+		if c != 1 {
+			t.Fatalf("element %d taken %d times", i, c)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package concurrent
+
+import (
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+)
+
+// Task is a unit of work executed by a WorkStealingPool. The worker running
+// the task is passed to it, so the task may fork subtasks.
+type Task func(w *Worker)
+
+// Worker is a goroutine of a WorkStealingPool. It runs the tasks from its own
+// deque first, then the submitted ones, then it steals the tasks of the other
+// workers.
+type Worker struct {
+	pool  *WorkStealingPool
+	id    int
+	deque *WorkStealingDeque
+}
+
+// ID returns the index of the worker in the pool.
+func (w *Worker) ID() int {
+	return w.id
+}
+
+// Fork schedules the task t on the deque of the worker. Must be called only
+// from a task running on the worker.
+func (w *Worker) Fork(t Task) {
+	w.pool.pending.Add(1)
+	w.deque.Push(t)
+}
+
+// WorkStealingPool is a fixed size pool of workers which balance the load by
+// stealing the tasks from each other. Idle workers back off using the
+// IdleStrategy.
+type WorkStealingPool struct {
+	mu      sync.RWMutex
+	workers []*Worker
+	inject  *SynchronizedRingQueue
+	idle    IdleStrategy
+	pending atomic.Int64
+	closed  atomic.Bool
+	wg      sync.WaitGroup
+}
+
+// NewWorkStealingPool returns pointer to a new WorkStealingPool instance and
+// starts n workers. The idle strategy is used by the workers which have found
+// no task; if it is nil, the yielding strategy is used.
+func NewWorkStealingPool(n int, idle IdleStrategy) *WorkStealingPool {
+	if n < 1 {
+		panic("number of workers must be positive")
+	}
+	if idle == nil {
+		idle = NewYeildingIdleStrategy()
+	}
+	p := &WorkStealingPool{
+		workers: make([]*Worker, n),
+		inject:  NewSynchronizedRingQueue(16),
+		idle:    idle,
+	}
+	for i := range p.workers {
+		p.workers[i] = &Worker{pool: p, id: i, deque: NewWorkStealingDeque(16)}
+	}
+	p.wg.Add(n)
+	for _, w := range p.workers {
+		go w.run()
+	}
+	return p
+}
+
+// Submit schedules the task t. Returns ErrClosed if the pool is closed.
+func (p *WorkStealingPool) Submit(t Task) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed.Load() {
+		return ErrClosed
+	}
+	p.pending.Add(1)
+	p.inject.Offer(t)
+	return nil
+}
+
+// Pending returns the number of the tasks scheduled but not completed yet.
+func (p *WorkStealingPool) Pending() int {
+	return int(p.pending.Load())
+}
+
+// Close stops accepting new tasks and waits until all the scheduled tasks,
+// including the forked ones, are completed and the workers exit.
+func (p *WorkStealingPool) Close() {
+	p.mu.Lock()
+	p.closed.Store(true)
+	p.mu.Unlock()
+	p.wg.Wait()
+}
+
+func (w *Worker) run() {
+	defer w.pool.wg.Done()
+	p := w.pool
+	for {
+		if t := w.next(); t != nil {
+			t(w)
+			p.pending.Add(-1)
+			continue
+		}
+		if p.closed.Load() && p.pending.Load() == 0 {
+			return
+		}
+		p.idle.Idle()
+	}
+}
+
+// next returns the next task to run, or nil if there is none.
+func (w *Worker) next() Task {
+	if t := w.deque.Pop(); t != nil {
+		return t.(Task)
+	}
+	if t := w.pool.inject.Poll(); t != nil {
+		return t.(Task)
+	}
+	ws := w.pool.workers
+	n := len(ws)
+	if n == 1 {
+		return nil
+	}
+	start := rand.IntN(n)
+	for i := 0; i < n; i++ {
+		v := ws[(start+i)%n]
+		if v == w {
+			continue
+		}
+		if t := v.deque.Steal(); t != nil {
+			return t.(Task)
+		}
+	}
+	return nil
+}
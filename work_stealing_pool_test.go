@@ -0,0 +1,85 @@
+package concurrent
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fib computes the Fibonacci number forking a subtask for each recursive
+// call.
+func fib(w *Worker, n int, res *atomic.Int64, wg *sync.WaitGroup) {
+	defer wg.Done()
+	if n < 2 {
+		res.Add(int64(n))
+		return
+	}
+	wg.Add(2)
+	w.Fork(func(w *Worker) { fib(w, n-1, res, wg) })
+	w.Fork(func(w *Worker) { fib(w, n-2, res, wg) })
+}
+
+func TestWorkStealingPoolForkJoin(t *testing.T) {
+	p := NewWorkStealingPool(4, NewYeildingIdleStrategy())
+	defer p.Close()
+
+	var res atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(1)
+	assert.Nil(t, p.Submit(func(w *Worker) { fib(w, 20, &res, &wg) }))
+	wg.Wait()
+	assert.Equal(t, int64(6765), res.Load())
+}
+
+func TestWorkStealingPoolStealing(t *testing.T) {
+	const n = 64
+
+	p := NewWorkStealingPool(4, NewSleepingIdleStrategy(time.Millisecond))
+
+	var mu sync.Mutex
+	ids := make(map[int]bool)
+	var wg sync.WaitGroup
+	wg.Add(n)
+	p.Submit(func(w *Worker) {
+		for i := 0; i < n; i++ {
+			w.Fork(func(w *Worker) {
+				mu.Lock()
+				ids[w.ID()] = true
+				mu.Unlock()
+				time.Sleep(time.Millisecond)
+				wg.Done()
+			})
+		}
+	})
+	wg.Wait()
+	p.Close()
+	assert.True(t, len(ids) > 1, "Forked tasks should be stolen by other workers")
+}
+
+func TestWorkStealingPoolClose(t *testing.T) {
+	const n = 1000
+
+	p := NewWorkStealingPool(2, NewYeildingIdleStrategy())
+	var count atomic.Int64
+	for i := 0; i < n; i++ {
+		assert.Nil(t, p.Submit(func(w *Worker) {
+			w.Fork(func(*Worker) { count.Add(1) })
+		}))
+	}
+	p.Close()
+	assert.Equal(t, int64(n), count.Load(), "Close should wait for forked tasks")
+	assert.Equal(t, 0, p.Pending())
+	assert.Equal(t, ErrClosed, p.Submit(func(*Worker) {}))
+
+	assert.Panics(t, func() { NewWorkStealingPool(0, nil) })
+
+	// nil idle strategy defaults to the yielding one
+	p = NewWorkStealingPool(1, nil)
+	done := make(chan struct{})
+	assert.Nil(t, p.Submit(func(*Worker) { close(done) }))
+	<-done
+	p.Close()
+}